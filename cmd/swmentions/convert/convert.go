@@ -0,0 +1,17 @@
+package convert
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(&idsCmd, &mentionsCmd, &papersCmd)
+}
+
+var Cmd = cobra.Command{
+	Use:   "convert",
+	Short: "Convert parts of the dataset to .pbl files",
+}
+
+var ErrConvert = errors.New("converting to proto")
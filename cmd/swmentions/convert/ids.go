@@ -1,45 +1,34 @@
-package main
+package convert
 
 import (
 	"bufio"
-	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/cobra"
-	"github.com/vbauerster/mpb"
-	"github.com/vbauerster/mpb/decor"
+	"github.com/willbeason/software-mentions/pkg/cli"
 	"github.com/willbeason/software-mentions/pkg/papers"
-	"golang.org/x/crypto/ssh/terminal"
-	"google.golang.org/protobuf/proto"
+	"github.com/willbeason/software-mentions/pkg/pbl"
+	"github.com/willbeason/software-mentions/pkg/progress"
 	"io"
 	"os"
 	"path/filepath"
-	"time"
 )
 
-func main() {
-	cmd.Flags().Bool("validate", false, "validate the transform is not lossy")
-	cmd.Flags().Bool("oa-link", true, "include oa_link in the output")
-	err := cmd.Execute()
-
-	if err != nil {
-		os.Exit(1)
-	}
+func init() {
+	idsCmd.Flags().Bool("validate", false, "validate the transform is not lossy")
+	idsCmd.Flags().Bool("oa-link", true, "include oa_link in the output")
 }
 
-var cmd = cobra.Command{
-	Use:     "paper-id-convert INFILE OUTFILE",
-	Short:   "Collect statistics about keys and values in .jsonl files",
-	Args:    cobra.ExactArgs(2),
-	Version: "0.1.0",
-	RunE:    runE,
+var idsCmd = cobra.Command{
+	Use:   "ids INFILE OUTFILE",
+	Short: "Convert paper IDs from .jsonl to .pbl",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runIds,
 }
 
-var ErrConvert = errors.New("converting to proto")
-
-func runE(cmd *cobra.Command, args []string) error {
+func runIds(cmd *cobra.Command, args []string) error {
 	validate, err := cmd.Flags().GetBool("validate")
 	if err != nil {
 		return err
@@ -56,8 +45,8 @@ func runE(cmd *cobra.Command, args []string) error {
 	}
 
 	outPath := args[1]
-	if ext := filepath.Ext(outPath); ext != ".pbl" {
-		return fmt.Errorf("%w: got output file extension %q but want %q", ErrConvert, ext, ".pbl")
+	if !pbl.HasExt(outPath) {
+		return fmt.Errorf("%w: got output file extension %q but want %q", ErrConvert, filepath.Ext(outPath), pbl.Ext)
 	}
 
 	file, err := os.Open(inPath)
@@ -72,31 +61,22 @@ func runE(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: reading stats of %q: %w", ErrConvert, inPath, err)
 	}
 
-	width, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	p, err := cli.NewProgress(cmd)
 	if err != nil {
-		return fmt.Errorf("%w: getting terminal size: %w", ErrConvert, err)
+		return err
 	}
-	p := mpb.New(mpb.WithWidth(width))
-	bar := p.AddBar(stats.Size(),
-		mpb.PrependDecorators(decor.AverageSpeed(decor.UnitKiB, "%.1f")),
-		mpb.AppendDecorators(decor.AverageETA(decor.ET_STYLE_GO)))
+	bar := p.AddBar(filepath.Base(inPath), stats.Size(), progress.Bytes)
 
-	outFile, err := os.Create(outPath)
+	writer, err := pbl.Create[*papers.PaperId](outPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrConvert, err)
+	}
 	defer func() {
-		if err := outFile.Close(); err != nil {
+		if err := writer.Close(); err != nil {
 			fmt.Printf("%v: closing output file %q: %v\n", ErrConvert, outPath, err)
 		}
 	}()
 
-	writer := bufio.NewWriter(outFile)
-	defer func() {
-		err = writer.Flush()
-		if err != nil {
-			fmt.Printf("%v: flushing output file %q: %v\n", ErrConvert, outPath, err)
-		}
-	}()
-
-	start := time.Now()
 	for {
 		line, err := reader.ReadBytes('\n')
 		if err != nil {
@@ -139,26 +119,12 @@ func runE(cmd *cobra.Command, args []string) error {
 			idProto.OaLink = ""
 		}
 
-		protoBytes, err := proto.Marshal(idProto)
-		if err != nil {
-			return err
-		}
-
-		nProtoBytes := len(protoBytes)
-
-		var buf []byte
-		buf = binary.AppendUvarint(buf, uint64(nProtoBytes))
-		_, err = writer.Write(buf)
-		if err != nil {
-			return fmt.Errorf("%w: writing length to %q: %w", ErrConvert, outPath, err)
-		}
-
-		_, err = writer.Write(protoBytes)
+		err = writer.Write(idProto)
 		if err != nil {
-			return fmt.Errorf("%w: writing proto to %q: %w", ErrConvert, outPath, err)
+			return fmt.Errorf("%w: writing to %q: %w", ErrConvert, outPath, err)
 		}
 
-		bar.IncrBy(len(line), time.Since(start))
+		bar.IncrBy(len(line))
 	}
 
 	return nil
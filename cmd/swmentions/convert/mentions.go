@@ -0,0 +1,376 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/cli"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"github.com/willbeason/software-mentions/pkg/pbl"
+	"github.com/willbeason/software-mentions/pkg/progress"
+	"github.com/willbeason/software-mentions/pkg/rejects"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func init() {
+	mentionsCmd.Flags().String("mention-counts", "", "file to write mention counts to")
+}
+
+var mentionsCmd = cobra.Command{
+	Use:   "mentions DIR OUTFILE",
+	Short: "Convert software mentions to protobuf",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runMentions,
+}
+
+var ErrMentionsConvert = fmt.Errorf("converting mentions")
+
+func runMentions(cmd *cobra.Command, args []string) error {
+	outPath := args[1]
+
+	p, err := cli.NewProgress(cmd)
+	if err != nil {
+		return err
+	}
+
+	mentionCountsPath, err := cmd.Flags().GetString("mention-counts")
+	if err != nil {
+		return err
+	}
+
+	inPath := args[0]
+	handler, closeRejects, err := cli.NewRejects(cmd, inPath)
+	if err != nil {
+		return err
+	}
+	defer closeRejects()
+
+	writer, err := pbl.Create[*papers.Mentions](outPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMentionsConvert, err)
+	}
+	defer func() {
+		err := writer.Close()
+		if err != nil {
+			slog.Error("closing writer", "err", err)
+		}
+	}()
+
+	// done is closed if writing fails, so reading stops early.
+	mentions := make(chan *papers.Mentions, 1000)
+	done := make(chan struct{})
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(mentions)
+		readErr <- processDirectory(inPath, p, 0, handler, done, mentions)
+	}()
+
+	counts := make(map[string]int)
+
+	var writeErr error
+	for mention := range mentions {
+		// Keep draining mentions so reading can finish.
+		if writeErr != nil {
+			continue
+		}
+
+		for _, m := range mention.Mentions {
+			counts[m.SoftwareName.NormalizedForm]++
+		}
+		err := writer.Write(mention)
+		if err != nil {
+			writeErr = fmt.Errorf("%w: writing to %q: %w", ErrMentionsConvert, outPath, err)
+			close(done)
+		}
+	}
+
+	err = <-readErr
+	if writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if mentionCountsPath == "" {
+		return nil
+	}
+
+	names := make([]string, len(counts))
+	i := 0
+	for name := range counts {
+		names[i] = name
+		i++
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return counts[names[i]] > counts[names[j]]
+	})
+
+	outFile, err := os.Create(mentionCountsPath)
+	if err != nil {
+		return fmt.Errorf("creating file for mention counts: %w", err)
+	}
+	defer func() {
+		err := outFile.Close()
+		if err != nil {
+			slog.Error("closing output file", "err", err)
+		}
+	}()
+
+	for _, name := range names {
+		_, err := fmt.Fprintf(outFile, "%s;%d\n", name, counts[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processDirectory sends the mentions in each file under inPath to out, until
+// done is closed.
+func processDirectory(inPath string, p *progress.Progress, depth int, handler *rejects.Handler, done <-chan struct{}, out chan<- *papers.Mentions) error {
+	names, err := os.ReadDir(inPath)
+	if err != nil {
+		return fmt.Errorf("%w: stat %q: %w", ErrMentionsConvert, inPath, err)
+	}
+
+	pathName := filepath.Base(inPath)
+
+	var bar *progress.Bar
+	switch depth {
+	case 0:
+		bar = p.AddBar(pathName, int64(len(names)), progress.Files)
+	case 1:
+		bar = p.AddBar(pathName, int64(len(names)), progress.Files, progress.RemoveOnComplete)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].Name() < names[j].Name()
+	})
+
+	for _, name := range names {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		entryPath := filepath.Join(inPath, name.Name())
+
+		if name.IsDir() {
+			err = processDirectory(entryPath, p, depth+1, handler, done, out)
+			if err != nil {
+				return err
+			}
+		} else if strings.HasSuffix(name.Name(), ".software.json") {
+			err = processFile(entryPath, done, out)
+			if err != nil {
+				err = handler.Handle(entryPath, err)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if bar != nil {
+			bar.Increment()
+		}
+	}
+
+	return nil
+}
+
+type MentionJson struct {
+	Mentions []Mention `json:"mentions"`
+}
+
+type Mention struct {
+	SoftwareName SoftwareName `json:"software-name"`
+
+	Type         string `json:"type"`
+	SoftwareType string `json:"software-type"`
+
+	Version  *SoftwareName `json:"version"`
+	Language *SoftwareName `json:"language"`
+	Url      *SoftwareName `json:"url"`
+
+	Context                   string             `json:"context"`
+	MentionContextAttributes  *ContextAttributes `json:"mentionContextAttributes"`
+	DocumentContextAttributes *ContextAttributes `json:"documentContextAttributes"`
+
+	References []Reference `json:"references"`
+}
+
+func (m *Mention) MarshalProto() *papers.Mention {
+	result := &papers.Mention{
+		SoftwareName:              m.SoftwareName.MarshalProto(),
+		Type:                      m.Type,
+		SoftwareType:              m.SoftwareType,
+		Language:                  m.Language.MarshalProto(),
+		Url:                       m.Url.MarshalProto(),
+		Context:                   m.Context,
+		MentionContextAttributes:  m.MentionContextAttributes.MarshalProto(),
+		DocumentContextAttributes: m.DocumentContextAttributes.MarshalProto(),
+	}
+
+	// Versions have the same JSON structure as names, but never a wikidataId.
+	if m.Version != nil {
+		result.Version = &papers.Version{
+			RawForm:        m.Version.RawForm,
+			NormalizedForm: m.Version.NormalizedForm,
+			OffsetStart:    m.Version.OffsetStart,
+			OffsetEnd:      m.Version.OffsetEnd,
+			BoundingBoxes:  marshalBoundingBoxes(m.Version.BoundingBoxes),
+		}
+	}
+
+	for _, reference := range m.References {
+		result.References = append(result.References, &papers.Reference{
+			RefKey: reference.RefKey,
+			Tei:    reference.Tei,
+		})
+	}
+
+	return result
+}
+
+type SoftwareName struct {
+	RawForm        string        `json:"rawForm"`
+	NormalizedForm string        `json:"normalizedForm"`
+	WikidataId     string        `json:"wikidataId"`
+	OffsetStart    int32         `json:"offsetStart"`
+	OffsetEnd      int32         `json:"offsetEnd"`
+	BoundingBoxes  []BoundingBox `json:"boundingBoxes"`
+}
+
+func (n *SoftwareName) MarshalProto() *papers.SoftwareName {
+	if n == nil {
+		return nil
+	}
+
+	return &papers.SoftwareName{
+		NormalizedForm: n.NormalizedForm,
+		WikidataId:     n.WikidataId,
+		RawForm:        n.RawForm,
+		OffsetStart:    n.OffsetStart,
+		OffsetEnd:      n.OffsetEnd,
+		BoundingBoxes:  marshalBoundingBoxes(n.BoundingBoxes),
+	}
+}
+
+type BoundingBox struct {
+	P int32   `json:"p"`
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+	W float32 `json:"w"`
+	H float32 `json:"h"`
+}
+
+func marshalBoundingBoxes(boxes []BoundingBox) []*papers.BoundingBox {
+	if len(boxes) == 0 {
+		return nil
+	}
+
+	result := make([]*papers.BoundingBox, len(boxes))
+	for i, box := range boxes {
+		result[i] = &papers.BoundingBox{
+			P: box.P,
+			X: box.X,
+			Y: box.Y,
+			W: box.W,
+			H: box.H,
+		}
+	}
+
+	return result
+}
+
+type ContextAttributes struct {
+	Used    *Attribute `json:"used"`
+	Created *Attribute `json:"created"`
+	Shared  *Attribute `json:"shared"`
+}
+
+func (a *ContextAttributes) MarshalProto() *papers.ContextAttributes {
+	if a == nil {
+		return nil
+	}
+
+	return &papers.ContextAttributes{
+		Used:    a.Used.MarshalProto(),
+		Created: a.Created.MarshalProto(),
+		Shared:  a.Shared.MarshalProto(),
+	}
+}
+
+type Attribute struct {
+	Value bool    `json:"value"`
+	Score float32 `json:"score"`
+}
+
+func (a *Attribute) MarshalProto() *papers.Attribute {
+	if a == nil {
+		return nil
+	}
+
+	return &papers.Attribute{
+		Value: a.Value,
+		Score: a.Score,
+	}
+}
+
+type Reference struct {
+	RefKey int32  `json:"refKey"`
+	Tei    string `json:"tei"`
+}
+
+func processFile(inPath string, done <-chan struct{}, out chan<- *papers.Mentions) error {
+	base := filepath.Base(inPath)
+	splits := strings.Split(base, ".")
+
+	if len(splits[0]) != 36 {
+		return nil
+	}
+
+	id, err := papers.ToUUID(splits[0])
+	if err != nil {
+		return fmt.Errorf("parsing UUID from filename %q: %w", inPath, err)
+	}
+
+	file, err := os.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := file.Close()
+		if err != nil {
+			slog.Error("closing input file", "err", err)
+		}
+	}()
+
+	decoder := json.NewDecoder(file)
+	mentionJson := &MentionJson{}
+	err = decoder.Decode(mentionJson)
+	if err != nil {
+		return fmt.Errorf("%w: decoding %q: %w", ErrMentionsConvert, inPath, err)
+	}
+
+	mention := &papers.Mentions{}
+	mention.Id = id
+	for _, m := range mentionJson.Mentions {
+		mention.Mentions = append(mention.Mentions, m.MarshalProto())
+	}
+
+	select {
+	case out <- mention:
+	case <-done:
+	}
+
+	return nil
+}
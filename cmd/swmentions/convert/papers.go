@@ -0,0 +1,135 @@
+package convert
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	bondsmith "github.com/willbeason/bondsmith"
+	"github.com/willbeason/software-mentions/pkg/cli"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"github.com/willbeason/software-mentions/pkg/pbl"
+	"github.com/willbeason/software-mentions/pkg/progress"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	papersCmd.Flags().Bool("validate", false, "validate the transform is not lossy")
+}
+
+var papersCmd = cobra.Command{
+	Use:   "papers INFILE OUTFILE",
+	Short: "Convert paper metadata from .jsonl to .pbl",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runPapers,
+}
+
+func runPapers(cmd *cobra.Command, args []string) error {
+	validate, err := cmd.Flags().GetBool("validate")
+	if err != nil {
+		return err
+	}
+
+	inPath := args[0]
+	if !strings.HasSuffix(inPath, ".jsonl") && !strings.HasSuffix(inPath, ".jsonl.gz") {
+		return fmt.Errorf("%w: got input file extension %q but want %q or %q", ErrConvert, filepath.Ext(inPath), ".jsonl", ".jsonl.gz")
+	}
+
+	outPath := args[1]
+	if !pbl.HasExt(outPath) {
+		return fmt.Errorf("%w: got output file extension %q but want %q", ErrConvert, filepath.Ext(outPath), pbl.Ext)
+	}
+
+	file, err := os.Open(inPath)
+	if err != nil {
+		return fmt.Errorf("%w: opening %q: %w", ErrConvert, inPath, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	stats, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("%w: reading stats of %q: %w", ErrConvert, inPath, err)
+	}
+
+	countReader := bondsmith.NewCountReader(file)
+	var inReader io.Reader = countReader
+	if strings.HasSuffix(inPath, ".gz") {
+		gzipReader, err := gzip.NewReader(countReader)
+		if err != nil {
+			return fmt.Errorf("%w: starting gzip reader stream for %q: %w", ErrConvert, inPath, err)
+		}
+		defer func() {
+			err := gzipReader.Close()
+			if err != nil {
+				slog.Error("closing gzip reader", "err", err)
+			}
+		}()
+		inReader = gzipReader
+	}
+	reader := bufio.NewReader(inReader)
+
+	p, err := cli.NewProgress(cmd)
+	if err != nil {
+		return err
+	}
+	bar := p.AddBar(filepath.Base(inPath), stats.Size(), progress.Bytes)
+
+	writer, err := pbl.Create[*papers.Paper](outPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrConvert, err)
+	}
+	defer func() {
+		err := writer.Close()
+		if err != nil {
+			slog.Error("closing output file", "path", outPath, "err", err)
+		}
+	}()
+
+	lastSeen := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+
+		entry := &papers.PaperJson{}
+		err = json.Unmarshal(line, entry)
+		if err != nil {
+			return fmt.Errorf("%w: unmarshalling JSON: %w", ErrConvert, err)
+		}
+
+		paperProto, err := entry.MarshalProto()
+		if err != nil {
+			return fmt.Errorf("%w: converting to proto: %w", ErrConvert, err)
+		}
+
+		if validate {
+			err = entry.CheckRoundTrip(paperProto)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrConvert, err)
+			}
+		}
+
+		err = writer.Write(paperProto)
+		if err != nil {
+			return fmt.Errorf("%w: writing to %q: %w", ErrConvert, outPath, err)
+		}
+
+		curProgress := int(countReader.Count())
+		bar.IncrBy(curProgress - lastSeen)
+		lastSeen = curProgress
+	}
+
+	return nil
+}
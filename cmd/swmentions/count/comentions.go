@@ -0,0 +1,84 @@
+package count
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/comentions"
+	"github.com/willbeason/software-mentions/pkg/tables"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// addComentionFlags registers the flags choosing which software and pairs of
+// software are counted.
+func addComentionFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("min-papers", 100, "minimum number of papers mentioning software for it to be counted")
+	cmd.Flags().Int("min-pair", 100, "minimum number of papers mentioning a pair of software for it to be counted")
+	cmd.Flags().String("ignore-file", "", "file listing software to ignore, one per line (default: a built-in list of generic words)")
+}
+
+// comentionCounts are the software and pairs of software counted in a
+// directory of Parquet tables.
+type comentionCounts struct {
+	counts *comentions.Counts
+	// softwareList is the software mentioned in at least --min-papers papers,
+	// most mentioned first.
+	softwareList []string
+	// dyads are the pairs of software in softwareList mentioned together in at
+	// least --min-pair papers, most mentioned first.
+	dyads        []comentions.MentionDyad
+	associations map[comentions.MentionDyad]*comentions.Association
+}
+
+// countComentions counts the software mentioned in the mentions table in
+// inDir, according to the flags added by addComentionFlags.
+func countComentions(cmd *cobra.Command, inDir string) (*comentionCounts, error) {
+	minPapers, err := cmd.Flags().GetInt("min-papers")
+	if err != nil {
+		return nil, err
+	}
+
+	minPair, err := cmd.Flags().GetInt("min-pair")
+	if err != nil {
+		return nil, err
+	}
+
+	ignore := comentions.DefaultIgnore
+	ignorePath, err := cmd.Flags().GetString("ignore-file")
+	if err != nil {
+		return nil, err
+	}
+	if ignorePath != "" {
+		ignore, err = comentions.ReadIgnore(ignorePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mentionsPath := filepath.Join(inDir, tables.Mentions+tables.ParquetExt)
+	softwareByPaper, err := comentions.ReadMentions(cmd.Context(), mentionsPath, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &comentionCounts{counts: comentions.Count(softwareByPaper, minPapers)}
+	c.softwareList = c.counts.RankSoftware(minPapers)
+	c.dyads = c.counts.RankDyads(minPair)
+	c.associations = c.counts.Associate(c.dyads)
+
+	return c, nil
+}
+
+// readSoftware reads the software table in inDir for node attributes. They are
+// left blank if there is no software table.
+func readSoftware(cmd *cobra.Command, inDir string) (map[string]*comentions.Software, error) {
+	softwarePath := filepath.Join(inDir, tables.Software+tables.ParquetExt)
+	_, err := os.Stat(softwarePath)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Warn("no software table, so wikidataId and softwareType will be blank", "path", softwarePath)
+		return nil, nil
+	}
+
+	return comentions.ReadSoftware(cmd.Context(), softwarePath)
+}
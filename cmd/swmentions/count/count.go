@@ -0,0 +1,14 @@
+package count
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Cmd.AddCommand(&licensesCmd, &mentionsCmd, &metricsCmd)
+}
+
+var Cmd = cobra.Command{
+	Use:   "count",
+	Short: "Count licenses and software mentions, and analyse the co-mention network",
+}
@@ -0,0 +1,102 @@
+package count
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/cli"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"github.com/willbeason/software-mentions/pkg/pbl"
+	"github.com/willbeason/software-mentions/pkg/progress"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+var licensesCmd = cobra.Command{
+	Use:   "licenses FILE",
+	Short: "Count the licenses in a .jsonl file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runLicenses,
+}
+
+var ErrCountLicenses = errors.New("counting licenses")
+
+func runLicenses(cmd *cobra.Command, args []string) error {
+	inPath := args[0]
+	if !pbl.HasExt(inPath) {
+		return fmt.Errorf("%w: got file extension %q but want %q", ErrCountLicenses, filepath.Ext(inPath), pbl.Ext)
+	}
+
+	// Safe to reuse entry in this case since we aren't passing it anywhere else.
+	// Unmarshal automatically resets entry.
+	entry := &papers.PaperId{}
+	reader, err := pbl.Open(inPath, func() *papers.PaperId {
+		return entry
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCountLicenses, err)
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			slog.Error("closing reader", "err", err)
+		}
+	}()
+
+	licenseMap := make([]int, len(papers.LicenseType_name))
+
+	stats, err := os.Stat(inPath)
+	if err != nil {
+		return fmt.Errorf("%w: reading stats of %q: %w", ErrCountLicenses, inPath, err)
+	}
+
+	p, err := cli.NewProgress(cmd)
+	if err != nil {
+		return err
+	}
+	bar := p.AddBar(filepath.Base(inPath), stats.Size(), progress.Bytes)
+
+	incrEvery := 1 << 10
+	i := 0
+	lastSeen := int64(0)
+	for entry, err := range reader.Read() {
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrCountLicenses, err)
+		}
+
+		licenseMap[entry.License]++
+		i++
+		if i%incrEvery == 0 {
+			consumed := reader.Consumed()
+			bar.IncrBy(int(consumed - lastSeen))
+			lastSeen = consumed
+		}
+	}
+	bar.IncrBy(int(reader.Consumed() - lastSeen))
+
+	licenses := make([]papers.LicenseType, len(licenseMap))
+	i = 0
+	for k := range licenseMap {
+		licenses[i] = papers.LicenseType(k)
+		i++
+	}
+
+	sort.Slice(licenseMap, func(i, j int) bool {
+		return licenseMap[licenses[i]] > licenseMap[licenses[j]]
+	})
+
+	for _, license := range licenses {
+		licenseStr, err := papers.ToLicenseString(license)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s;%d\n", licenseStr, licenseMap[license])
+	}
+	// Add newline to prevent last line of output from being consumed by progress bar.
+	fmt.Println()
+
+	return nil
+}
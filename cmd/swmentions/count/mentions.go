@@ -0,0 +1,250 @@
+package count
+
+import (
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/arrow/memory"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/comentions"
+	"github.com/willbeason/software-mentions/pkg/tables"
+	"log/slog"
+)
+
+func init() {
+	addComentionFlags(&mentionsCmd)
+	mentionsCmd.Flags().Int("top", 20, "number of the most mentioned software and pairs to print")
+	mentionsCmd.Flags().String("software-out", "", "file to write the ranked software table to, as .csv or .parquet")
+	mentionsCmd.Flags().String("pairs-out", "", "file to write the ranked table of pairs of software to, as .csv or .parquet")
+	mentionsCmd.Flags().StringSlice("graph-out", nil, "files to write the co-mention network to, as .graphml, .gexf, .net (Pajek), or an edge list in .parquet or .csv")
+	mentionsCmd.Flags().String("edge-measure", string(comentions.MeasureNPMI), "association measure to record on the edges of --graph-out")
+	mentionsCmd.Flags().String("rank-by", string(comentions.MeasurePapers), fmt.Sprintf("measure to rank pairs of software by, one of %v", comentions.Measures))
+}
+
+var mentionsCmd = cobra.Command{
+	Use:   "mentions DIR",
+	Short: "Count software mentions and co-mentions in a directory of Parquet tables",
+	Long: `Count software mentions and co-mentions in a directory of Parquet tables.
+
+DIR is an output directory of extract software. Software mentioned in at least
+--min-papers papers is ranked by the number of papers mentioning it, and pairs
+of such software mentioned together in at least --min-pair papers are ranked
+by --rank-by. The top few of each are printed, and the full rankings may be
+written with --software-out and --pairs-out.
+
+Since raw counts favour pairs of popular software, pairs are also scored by how
+much more often they are mentioned together than if papers mentioned each
+independently:
+
+  pmi      pointwise mutual information, in bits
+  npmi     PMI normalized to between -1 and 1
+  jaccard  papers mentioning both over papers mentioning either
+  lift     papers mentioning both over the number expected
+  g2       log-likelihood ratio (G²) of the 2x2 table of papers
+  p        one-sided p-value of a hypergeometric test
+  q        p adjusted for the number of pairs (Benjamini-Hochberg)
+
+Probabilities are relative to the papers mentioning any software.
+
+The network of ranked software and pairs may be written with --graph-out for
+tools such as Gephi, igraph, and networkx. Edges are weighted by the number of
+papers mentioning both, and also record --edge-measure. GraphML and GEXF nodes
+record the number of papers mentioning each and their wikidataId and
+softwareType from the software table in DIR, as does the --software-out table,
+which may be used as the node table of an edge list.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMentions,
+}
+
+var ErrCountMentions = errors.New("counting software mentions")
+
+var (
+	softwareRankSchema = arrow.NewSchema([]arrow.Field{
+		{Name: "rank", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "softwareId", Type: arrow.BinaryTypes.String},
+		{Name: "papers", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "wikidataId", Type: arrow.BinaryTypes.String},
+		{Name: "softwareType", Type: arrow.BinaryTypes.String},
+	}, nil)
+
+	pairRankSchema = arrow.NewSchema([]arrow.Field{
+		{Name: "rank", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "software1", Type: arrow.BinaryTypes.String},
+		{Name: "software2", Type: arrow.BinaryTypes.String},
+		{Name: "papers", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "pmi", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "npmi", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "jaccard", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "lift", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "g2", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "p", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "q", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+)
+
+func runMentions(cmd *cobra.Command, args []string) error {
+	inDir := args[0]
+
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		return err
+	}
+	if top < 0 {
+		return fmt.Errorf("%w: --top must not be negative, not %d", ErrCountMentions, top)
+	}
+
+	rankByString, err := cmd.Flags().GetString("rank-by")
+	if err != nil {
+		return err
+	}
+	rankBy, err := comentions.ParseMeasure(rankByString)
+	if err != nil {
+		return err
+	}
+
+	edgeMeasureString, err := cmd.Flags().GetString("edge-measure")
+	if err != nil {
+		return err
+	}
+	edgeMeasure, err := comentions.ParseMeasure(edgeMeasureString)
+	if err != nil {
+		return err
+	}
+
+	softwareOut, err := cmd.Flags().GetString("software-out")
+	if err != nil {
+		return err
+	}
+	pairsOut, err := cmd.Flags().GetString("pairs-out")
+	if err != nil {
+		return err
+	}
+	for _, outPath := range []string{softwareOut, pairsOut} {
+		if outPath == "" {
+			continue
+		}
+		err = tables.CheckFormat(outPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	graphOuts, err := cmd.Flags().GetStringSlice("graph-out")
+	if err != nil {
+		return err
+	}
+	for _, outPath := range graphOuts {
+		err = comentions.CheckNetworkFormat(outPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	c, err := countComentions(cmd, inDir)
+	if err != nil {
+		return err
+	}
+	counts, softwareList, comentionsList, associations := c.counts, c.softwareList, c.dyads, c.associations
+
+	fmt.Println(len(softwareList))
+
+	for i, softwareId := range softwareList[:min(top, len(softwareList))] {
+		fmt.Printf("%d;%s;%d\n", i, softwareId, counts.Software[softwareId])
+	}
+
+	comentions.SortDyads(comentionsList, associations, rankBy)
+
+	for _, dyad := range comentionsList[:min(top, len(comentionsList))] {
+		if rankBy == comentions.MeasurePapers {
+			fmt.Printf("%s;%s;%d\n", dyad.A, dyad.B, counts.Dyads[dyad])
+		} else {
+			fmt.Printf("%s;%s;%d;%g\n", dyad.A, dyad.B, counts.Dyads[dyad], associations[dyad].Value(rankBy))
+		}
+	}
+
+	var software map[string]*comentions.Software
+	if softwareOut != "" || len(graphOuts) > 0 {
+		software, err = readSoftware(cmd, inDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if softwareOut != "" {
+		err = writeSoftwareRanks(softwareOut, counts, softwareList, software)
+		if err != nil {
+			return err
+		}
+		slog.Info("wrote software ranking", "path", softwareOut, "software", len(softwareList))
+	}
+
+	if pairsOut != "" {
+		err = writePairRanks(pairsOut, comentionsList, associations)
+		if err != nil {
+			return err
+		}
+		slog.Info("wrote ranking of pairs of software", "path", pairsOut, "pairs", len(comentionsList))
+	}
+
+	if len(graphOuts) > 0 {
+		network := comentions.NewNetwork(counts, softwareList, comentionsList, associations, software, edgeMeasure)
+		for _, outPath := range graphOuts {
+			err = comentions.WriteNetwork(outPath, network)
+			if err != nil {
+				return err
+			}
+			slog.Info("wrote co-mention network", "path", outPath, "nodes", len(network.Nodes), "edges", len(network.Edges))
+		}
+	}
+
+	return nil
+}
+
+func writeSoftwareRanks(outPath string, counts *comentions.Counts, softwareList []string, software map[string]*comentions.Software) error {
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), softwareRankSchema)
+	defer builder.Release()
+
+	for i, softwareId := range softwareList {
+		builder.Field(0).(*array.Int64Builder).Append(int64(i + 1))
+		builder.Field(1).(*array.StringBuilder).Append(softwareId)
+		builder.Field(2).(*array.Int64Builder).Append(int64(counts.Software[softwareId]))
+
+		var wikidataId, softwareType string
+		if s := software[softwareId]; s != nil {
+			wikidataId, softwareType = s.WikidataId, s.SoftwareType
+		}
+		builder.Field(3).(*array.StringBuilder).Append(wikidataId)
+		builder.Field(4).(*array.StringBuilder).Append(softwareType)
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	return tables.Write(outPath, record)
+}
+
+func writePairRanks(outPath string, comentionsList []comentions.MentionDyad, associations map[comentions.MentionDyad]*comentions.Association) error {
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), pairRankSchema)
+	defer builder.Release()
+
+	for i, dyad := range comentionsList {
+		association := associations[dyad]
+		builder.Field(0).(*array.Int64Builder).Append(int64(i + 1))
+		builder.Field(1).(*array.StringBuilder).Append(dyad.A)
+		builder.Field(2).(*array.StringBuilder).Append(dyad.B)
+		builder.Field(3).(*array.Int64Builder).Append(int64(association.Papers))
+		builder.Field(4).(*array.Float64Builder).Append(association.PMI)
+		builder.Field(5).(*array.Float64Builder).Append(association.NPMI)
+		builder.Field(6).(*array.Float64Builder).Append(association.Jaccard)
+		builder.Field(7).(*array.Float64Builder).Append(association.Lift)
+		builder.Field(8).(*array.Float64Builder).Append(association.G2)
+		builder.Field(9).(*array.Float64Builder).Append(association.PValue)
+		builder.Field(10).(*array.Float64Builder).Append(association.QValue)
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	return tables.Write(outPath, record)
+}
@@ -0,0 +1,214 @@
+package count
+
+import (
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/arrow/memory"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/comentions"
+	"github.com/willbeason/software-mentions/pkg/graph"
+	"github.com/willbeason/software-mentions/pkg/tables"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+func init() {
+	addComentionFlags(&metricsCmd)
+	metricsCmd.Flags().String("weight", string(comentions.MeasurePapers), "measure to weight edges by; edges with weights of zero or less are left out")
+	metricsCmd.Flags().Float64("damping", 0.85, "damping factor of PageRank")
+	metricsCmd.Flags().Int("samples", 500, "number of sources to estimate betweenness from, or 0 to compute it exactly")
+	metricsCmd.Flags().Float64("resolution", 1, "resolution of community detection; higher values find smaller communities")
+	metricsCmd.Flags().Int64("seed", 1, "seed for sampling betweenness and ordering community detection")
+	metricsCmd.Flags().Int("top", 10, "number of the largest communities to print")
+}
+
+var metricsCmd = cobra.Command{
+	Use:   "metrics DIR OUTFILE",
+	Short: "Compute network metrics and communities of software in the co-mention graph",
+	Long: `Compute network metrics and communities of software in the co-mention graph.
+
+DIR is an output directory of extract software. As in count mentions, software
+mentioned in at least --min-papers papers are nodes, and pairs of them mentioned
+together in at least --min-pair papers are edges weighted by --weight. For each
+piece of software, OUTFILE records as .csv or .parquet its
+
+  degree       number of software it is mentioned with
+  strength     total weight of its edges
+  pageRank     weighted PageRank
+  betweenness  shortest paths through it, taking edge lengths as the inverse
+               of their weights, estimated from --samples sources
+  component    connected component, numbered from the largest
+  community    Louvain community, numbered from the largest
+
+The largest communities and their most mentioned software are printed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMetrics,
+}
+
+var ErrMetrics = errors.New("computing co-mention network metrics")
+
+var metricsSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "softwareId", Type: arrow.BinaryTypes.String},
+	{Name: "papers", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "degree", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "strength", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "pageRank", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "betweenness", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "component", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "community", Type: arrow.PrimitiveTypes.Int64},
+}, nil)
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	inDir := args[0]
+	outPath := args[1]
+
+	err := tables.CheckFormat(outPath)
+	if err != nil {
+		return err
+	}
+
+	weightString, err := cmd.Flags().GetString("weight")
+	if err != nil {
+		return err
+	}
+	weight, err := comentions.ParseMeasure(weightString)
+	if err != nil {
+		return err
+	}
+	if weight.Ascending() {
+		return fmt.Errorf("%w: --weight can't be %s, since smaller values mean stronger association", ErrMetrics, weight)
+	}
+
+	damping, err := cmd.Flags().GetFloat64("damping")
+	if err != nil {
+		return err
+	}
+	if damping < 0 || damping >= 1 {
+		return fmt.Errorf("%w: --damping must be at least 0 and less than 1, not %g", ErrMetrics, damping)
+	}
+
+	samples, err := cmd.Flags().GetInt("samples")
+	if err != nil {
+		return err
+	}
+	if samples < 0 {
+		return fmt.Errorf("%w: --samples must not be negative, not %d", ErrMetrics, samples)
+	}
+
+	resolution, err := cmd.Flags().GetFloat64("resolution")
+	if err != nil {
+		return err
+	}
+	if resolution <= 0 {
+		return fmt.Errorf("%w: --resolution must be positive, not %g", ErrMetrics, resolution)
+	}
+
+	seed, err := cmd.Flags().GetInt64("seed")
+	if err != nil {
+		return err
+	}
+
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		return err
+	}
+	if top < 0 {
+		return fmt.Errorf("%w: --top must not be negative, not %d", ErrMetrics, top)
+	}
+
+	c, err := countComentions(cmd, inDir)
+	if err != nil {
+		return err
+	}
+
+	g := graph.New()
+	for _, softwareId := range c.softwareList {
+		g.Node(softwareId)
+	}
+	dropped := 0
+	for _, dyad := range c.dyads {
+		w := c.associations[dyad].Value(weight)
+		if !(w > 0) {
+			dropped++
+			continue
+		}
+		g.AddEdge(g.Node(dyad.A), g.Node(dyad.B), w)
+	}
+	if dropped > 0 {
+		slog.Info("left out edges without positive weight", "weight", weight, "edges", dropped)
+	}
+
+	slog.Info("computing PageRank", "nodes", g.Len())
+	pageRank := g.PageRank(damping)
+
+	slog.Info("computing betweenness", "samples", samples)
+	betweenness := g.Betweenness(samples, rand.New(rand.NewSource(seed)))
+
+	components := g.Components()
+
+	communities, modularity := g.Communities(resolution, rand.New(rand.NewSource(seed)))
+	slog.Info("found communities", "components", countLabels(components), "communities", countLabels(communities),
+		"modularity", modularity)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), metricsSchema)
+	defer builder.Release()
+
+	for i := range g.Len() {
+		softwareId := g.Name(i)
+		builder.Field(0).(*array.StringBuilder).Append(softwareId)
+		builder.Field(1).(*array.Int64Builder).Append(int64(c.counts.Software[softwareId]))
+		builder.Field(2).(*array.Int64Builder).Append(int64(g.Degree(i)))
+		builder.Field(3).(*array.Float64Builder).Append(g.Strength(i))
+		builder.Field(4).(*array.Float64Builder).Append(pageRank[i])
+		builder.Field(5).(*array.Float64Builder).Append(betweenness[i])
+		builder.Field(6).(*array.Int64Builder).Append(int64(components[i]))
+		builder.Field(7).(*array.Int64Builder).Append(int64(communities[i]))
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	err = tables.Write(outPath, record)
+	if err != nil {
+		return err
+	}
+
+	printCommunities(g, c.counts, communities, top)
+
+	return nil
+}
+
+func countLabels(labels []int) int {
+	n := 0
+	for _, label := range labels {
+		n = max(n, label+1)
+	}
+	return n
+}
+
+// printCommunities prints the size and most mentioned software of the top
+// largest communities.
+func printCommunities(g *graph.Graph, counts *comentions.Counts, communities []int, top int) {
+	members := make([][]string, min(top, countLabels(communities)))
+	for i, community := range communities {
+		if community < len(members) {
+			members[community] = append(members[community], g.Name(i))
+		}
+	}
+
+	for community, softwareIds := range members {
+		// Break ties by name so the same seed prints the same members.
+		sort.Slice(softwareIds, func(i, j int) bool {
+			ci, cj := counts.Software[softwareIds[i]], counts.Software[softwareIds[j]]
+			if ci != cj {
+				return ci > cj
+			}
+			return softwareIds[i] < softwareIds[j]
+		})
+		fmt.Printf("%d;%d;%s\n", community, len(softwareIds), strings.Join(softwareIds[:min(5, len(softwareIds))], ","))
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package extract
 
 import (
 	"compress/gzip"
@@ -15,23 +15,16 @@ import (
 	"github.com/willbeason/bondsmith/jsonio"
 	"github.com/willbeason/software-mentions/pkg/tables"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 )
 
-func main() {
-	err := cmd.Execute()
-	if err != nil {
-		os.Exit(1)
-	}
-}
-
-var cmd = cobra.Command{
-	Use:     "extract-columns [papers|software] IN_DIR OUT_DIR",
-	Short:   "converts parts of the dataset into the Apache Parquet format",
-	Args:    cobra.ExactArgs(3),
-	Version: "0.1.0",
-	RunE:    runE,
+var Cmd = cobra.Command{
+	Use:   "extract [papers|software] IN_DIR OUT_DIR",
+	Short: "converts parts of the dataset into the Apache Parquet format",
+	Args:  cobra.ExactArgs(3),
+	RunE:  runE,
 }
 
 func runE(_ *cobra.Command, args []string) error {
@@ -45,7 +38,7 @@ func runE(_ *cobra.Command, args []string) error {
 	defer func() {
 		err = inFile.Close()
 		if err != nil {
-			fmt.Println(err)
+			slog.Error("closing input file", "err", err)
 		}
 	}()
 
@@ -227,7 +220,7 @@ func writeRecords(schema *arrow.Schema, recordBuilder *array.RecordBuilder, outD
 	defer func() {
 		err = writer.Close()
 		if err != nil {
-			fmt.Println(err)
+			slog.Error("closing writer", "err", err)
 		}
 	}()
 
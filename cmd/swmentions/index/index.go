@@ -0,0 +1,151 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"github.com/willbeason/software-mentions/pkg/pbl"
+	"google.golang.org/protobuf/encoding/protojson"
+	"log/slog"
+	"path/filepath"
+)
+
+func init() {
+	Cmd.PersistentFlags().String("type", typeIds, "type of record in the file: ids or mentions")
+	Cmd.AddCommand(&buildCmd, &getCmd)
+}
+
+var Cmd = cobra.Command{
+	Use:   "index",
+	Short: "Build and query UUID indices of .pbl files",
+}
+
+var buildCmd = cobra.Command{
+	Use:   "build FILE",
+	Short: "Write the index of a .pbl file to FILE.idx",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBuild,
+}
+
+var getCmd = cobra.Command{
+	Use:   "get FILE UUID",
+	Short: "Print the record for a paper UUID as JSON",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runGet,
+}
+
+const (
+	typeIds      = "ids"
+	typeMentions = "mentions"
+)
+
+func runBuild(cmd *cobra.Command, args []string) error {
+	inPath := args[0]
+	if !pbl.HasExt(inPath) {
+		return fmt.Errorf("%w: got file extension %q but want %q", pbl.ErrIndex, filepath.Ext(inPath), pbl.Ext)
+	}
+
+	recordType, err := cmd.Flags().GetString("type")
+	if err != nil {
+		return err
+	}
+
+	var n int
+	switch recordType {
+	case typeIds:
+		n, err = pbl.BuildIndex(inPath, func() *papers.PaperId {
+			return &papers.PaperId{}
+		})
+	case typeMentions:
+		n, err = pbl.BuildIndex(inPath, func() *papers.Mentions {
+			return &papers.Mentions{}
+		})
+	default:
+		return fmt.Errorf("%w: --type must be either %s or %s, not %q", pbl.ErrIndex, typeIds, typeMentions, recordType)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("indexed %d records in %q\n", n, pbl.IndexPath(inPath))
+
+	return nil
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	inPath := args[0]
+	id := args[1]
+
+	recordType, err := cmd.Flags().GetString("type")
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch recordType {
+	case typeIds:
+		entry, err := lookup(inPath, id, func() *papers.PaperId {
+			return &papers.PaperId{}
+		})
+		if err != nil {
+			return err
+		}
+
+		// Print in the same format as the source dataset.
+		idJson := &papers.PaperIdJson{}
+		err = idJson.UnmarshalProto(entry)
+		if err != nil {
+			return err
+		}
+		out, err = json.Marshal(idJson)
+		if err != nil {
+			return err
+		}
+	case typeMentions:
+		entry, err := lookup(inPath, id, func() *papers.Mentions {
+			return &papers.Mentions{}
+		})
+		if err != nil {
+			return err
+		}
+
+		out, err = protojson.Marshal(entry)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: --type must be either %s or %s, not %q", pbl.ErrIndex, typeIds, typeMentions, recordType)
+	}
+
+	fmt.Println(string(out))
+
+	return nil
+}
+
+func lookup[T pbl.Keyed](inPath, id string, newT func() T) (T, error) {
+	var zero T
+
+	uuid, err := papers.ToUUID(id)
+	if err != nil {
+		return zero, err
+	}
+
+	reader, err := pbl.Open(inPath, newT)
+	if err != nil {
+		return zero, err
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			slog.Error("closing reader", "err", err)
+		}
+	}()
+
+	entry, err := reader.Lookup(uuid)
+	if err != nil {
+		return zero, fmt.Errorf("looking up %q in %q: %w", id, inPath, err)
+	}
+
+	return entry, nil
+}
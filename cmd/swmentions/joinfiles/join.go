@@ -0,0 +1,300 @@
+package joinfiles
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/bondsmith/jsonio"
+	"github.com/willbeason/software-mentions/pkg/join"
+	"github.com/willbeason/software-mentions/pkg/jsonl"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"github.com/willbeason/software-mentions/pkg/pbl"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"iter"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	Cmd.Flags().String("kind", string(join.Inner), "which records to keep: inner, left, or full")
+	Cmd.Flags().String("left-key", jsonl.DefaultKeyPath, "dotted path of the key field in LEFT, if it is .jsonl")
+	Cmd.Flags().String("right-key", jsonl.DefaultKeyPath, "dotted path of the key field in RIGHT, if it is .jsonl")
+	Cmd.Flags().String("key-type", string(jsonl.KeyUUID), "how to compare keys of .jsonl inputs: uuid, string, or number")
+	Cmd.Flags().String("left-type", "", "type of record in LEFT, if it is .pbl: ids, mentions, or papers")
+	Cmd.Flags().String("right-type", "", "type of record in RIGHT, if it is .pbl: ids, mentions, or papers")
+	Cmd.Flags().String("left-name", "left", "field to write LEFT records to")
+	Cmd.Flags().String("right-name", "right", "field to write RIGHT records to")
+}
+
+var Cmd = cobra.Command{
+	Use:   "join LEFT RIGHT OUTFILE",
+	Short: "Join two files sorted by paper UUID into combined .jsonl records",
+	Long: `Join two files sorted by paper UUID into combined .jsonl records.
+
+Inputs may be .jsonl, .jsonl.gz, or .pbl files, and must already be sorted by
+key, for example with sort. Each output record has the key as "id" and
+the joined records under --left-name and --right-name. Only the RIGHT records
+sharing the current key are held in memory.`,
+	Args: cobra.ExactArgs(3),
+	RunE: runE,
+}
+
+const (
+	typeIds      = "ids"
+	typePapers   = "papers"
+	typeMentions = "mentions"
+)
+
+var ErrJoinFiles = errors.New("joining files")
+
+func runE(cmd *cobra.Command, args []string) error {
+	leftPath, rightPath, outPath := args[0], args[1], args[2]
+
+	if !strings.HasSuffix(outPath, ".jsonl") && !strings.HasSuffix(outPath, ".jsonl.gz") {
+		return fmt.Errorf("%w: got output file extension %q but want %q or %q", ErrJoinFiles, filepath.Ext(outPath), ".jsonl", ".jsonl.gz")
+	}
+
+	flags := make(map[string]string)
+	for _, name := range []string{"kind", "left-key", "right-key", "key-type", "left-type", "right-type", "left-name", "right-name"} {
+		value, err := cmd.Flags().GetString(name)
+		if err != nil {
+			return err
+		}
+		flags[name] = value
+	}
+
+	kind := join.Kind(flags["kind"])
+	switch kind {
+	case join.Inner, join.Left, join.FullOuter:
+	default:
+		return fmt.Errorf("%w: --kind must be inner, left, or full, not %q", ErrJoinFiles, kind)
+	}
+
+	keyType := jsonl.KeyType(flags["key-type"])
+	switch keyType {
+	case jsonl.KeyUUID, jsonl.KeyString, jsonl.KeyNumber:
+	default:
+		return fmt.Errorf("%w: --key-type must be uuid, string, or number, not %q", ErrJoinFiles, keyType)
+	}
+	if keyType != jsonl.KeyUUID && (pbl.HasExt(leftPath) || pbl.HasExt(rightPath)) {
+		return fmt.Errorf("%w: .pbl files are keyed by UUID so --key-type must be uuid", ErrJoinFiles)
+	}
+
+	left, err := openInput(leftPath, flags["left-type"], flags["left-key"], keyType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := left.close()
+		if err != nil {
+			slog.Error("closing LEFT", "err", err)
+		}
+	}()
+
+	right, err := openInput(rightPath, flags["right-type"], flags["right-key"], keyType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := right.close()
+		if err != nil {
+			slog.Error("closing RIGHT", "err", err)
+		}
+	}()
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("%w: creating %q: %w", ErrJoinFiles, outPath, err)
+	}
+	defer func() {
+		err := outFile.Close()
+		if err != nil {
+			slog.Error("closing output file", "err", err)
+		}
+	}()
+
+	var out io.Writer = outFile
+	if strings.HasSuffix(outPath, ".gz") {
+		gzipWriter := gzip.NewWriter(outFile)
+		defer func() {
+			err := gzipWriter.Close()
+			if err != nil {
+				slog.Error("closing gzip stream", "err", err)
+			}
+		}()
+		out = gzipWriter
+	}
+
+	writer := bufio.NewWriter(out)
+	defer func() {
+		err := writer.Flush()
+		if err != nil {
+			slog.Error("flushing output", "err", err)
+		}
+	}()
+	encoder := json.NewEncoder(writer)
+
+	keyOf := func(r record) ([]byte, error) {
+		return r.key, nil
+	}
+
+	rows := 0
+	for row, err := range join.Join(kind, left.records, keyOf, right.records, keyOf) {
+		if err != nil {
+			return err
+		}
+
+		combined := map[string]any{
+			"id": jsonl.FormatKey(row.Key, keyType),
+		}
+		if row.HasLeft {
+			combined[flags["left-name"]] = row.Left.value
+		}
+		if row.HasRight {
+			combined[flags["right-name"]] = row.Right.value
+		}
+
+		err = encoder.Encode(combined)
+		if err != nil {
+			return fmt.Errorf("%w: writing to %q: %w", ErrJoinFiles, outPath, err)
+		}
+		rows++
+	}
+
+	fmt.Printf("wrote %d joined records to %q\n", rows, outPath)
+
+	return nil
+}
+
+// record is a value ready to be marshalled to JSON, and the key it is sorted
+// by.
+type record struct {
+	key   []byte
+	value any
+}
+
+type input struct {
+	records iter.Seq2[record, error]
+	close   func() error
+}
+
+// openInput reads path as a stream of records, converting .pbl records to the
+// same JSON format as the source dataset.
+func openInput(path, recordType, keyPath string, keyType jsonl.KeyType) (*input, error) {
+	switch {
+	case pbl.HasExt(path):
+		switch recordType {
+		case typeIds:
+			return openPbl(path, func() *papers.PaperId {
+				return &papers.PaperId{}
+			}, func(x *papers.PaperId) (record, error) {
+				idJson := &papers.PaperIdJson{}
+				err := idJson.UnmarshalProto(x)
+				return record{key: x.GetId().GetId(), value: idJson}, err
+			})
+		case typeMentions:
+			return openPbl(path, func() *papers.Mentions {
+				return &papers.Mentions{}
+			}, func(x *papers.Mentions) (record, error) {
+				out, err := protojson.Marshal(x)
+				return record{key: x.GetId().GetId(), value: json.RawMessage(out)}, err
+			})
+		case typePapers:
+			return openPbl(path, func() *papers.Paper {
+				return &papers.Paper{}
+			}, func(x *papers.Paper) (record, error) {
+				id, err := papers.ToUUID(x.GetId())
+				if err != nil {
+					return record{}, err
+				}
+
+				paperJson := &papers.PaperJson{}
+				paperJson.UnmarshalProto(x)
+				return record{key: id.GetId(), value: paperJson}, nil
+			})
+		default:
+			return nil, fmt.Errorf("%w: record type of %q must be %s, %s, or %s, not %q", ErrJoinFiles, path, typeIds, typeMentions, typePapers, recordType)
+		}
+	case strings.HasSuffix(path, ".jsonl"), strings.HasSuffix(path, ".jsonl.gz"):
+		return openJsonl(path, keyPath, keyType)
+	default:
+		return nil, fmt.Errorf("%w: got file extension %q for %q but want %q, %q, or %q", ErrJoinFiles, filepath.Ext(path), path, ".jsonl", ".jsonl.gz", pbl.Ext)
+	}
+}
+
+func openPbl[T proto.Message](path string, newT func() T, toRecord func(T) (record, error)) (*input, error) {
+	reader, err := pbl.Open(path, newT)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJoinFiles, err)
+	}
+
+	records := func(yield func(record, error) bool) {
+		for entry, err := range reader.Read() {
+			if err != nil {
+				yield(record{}, err)
+				return
+			}
+
+			r, err := toRecord(entry)
+			if err != nil {
+				yield(record{}, fmt.Errorf("converting record in %q: %w", path, err))
+				return
+			}
+
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+
+	return &input{records: records, close: reader.Close}, nil
+}
+
+func openJsonl(path, keyPath string, keyType jsonl.KeyType) (*input, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: opening %q: %w", ErrJoinFiles, path, err)
+	}
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		reader, err = gzip.NewReader(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("%w: starting gzip reader stream for %q: %w", ErrJoinFiles, path, err)
+		}
+	}
+
+	entries := jsonio.NewReader(reader, func() *map[string]any {
+		v := make(map[string]any)
+		return &v
+	})
+
+	records := func(yield func(record, error) bool) {
+		for v, err := range entries.Read() {
+			if err != nil {
+				yield(record{}, err)
+				return
+			}
+
+			key, err := jsonl.Key(*v, keyPath, keyType)
+			if err != nil {
+				yield(record{}, fmt.Errorf("reading key of entry in %q: %w", path, err))
+				return
+			}
+
+			if !yield(record{key: key, value: *v}, nil) {
+				return
+			}
+		}
+	}
+
+	return &input{records: records, close: file.Close}, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/cmd/swmentions/convert"
+	"github.com/willbeason/software-mentions/cmd/swmentions/count"
+	"github.com/willbeason/software-mentions/cmd/swmentions/extract"
+	"github.com/willbeason/software-mentions/cmd/swmentions/index"
+	"github.com/willbeason/software-mentions/cmd/swmentions/joinfiles"
+	"github.com/willbeason/software-mentions/cmd/swmentions/merge"
+	"github.com/willbeason/software-mentions/cmd/swmentions/rmprocessed"
+	"github.com/willbeason/software-mentions/cmd/swmentions/sortjsonl"
+	"github.com/willbeason/software-mentions/cmd/swmentions/stats"
+	"github.com/willbeason/software-mentions/cmd/swmentions/trends"
+	"github.com/willbeason/software-mentions/pkg/cli"
+	"os"
+)
+
+func main() {
+	cli.AddFlags(&cmd)
+	cmd.AddCommand(
+		&merge.Cmd,
+		&sortjsonl.Cmd,
+		&stats.Cmd,
+		&convert.Cmd,
+		&extract.Cmd,
+		&count.Cmd,
+		&rmprocessed.Cmd,
+		&joinfiles.Cmd,
+		&index.Cmd,
+		&trends.Cmd,
+	)
+
+	err := cmd.Execute()
+	cli.Teardown()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+var cmd = cobra.Command{
+	Use:               "swmentions",
+	Short:             "Process and analyze the software mentions dataset",
+	Version:           "0.1.0",
+	PersistentPreRunE: cli.Setup,
+}
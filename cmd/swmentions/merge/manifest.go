@@ -0,0 +1,164 @@
+package merge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestName is the file in the output directory recording which
+// directories merge has finished.
+const ManifestName = "manifest.json"
+
+var ErrManifest = errors.New("merge manifest")
+
+// Manifest records the top-level directories merge has finished, so an
+// interrupted merge can resume without redoing them.
+type Manifest struct {
+	// Directories is keyed by the name of each finished directory.
+	Directories map[string]*DirectoryManifest `json:"directories"`
+}
+
+type DirectoryManifest struct {
+	Completed time.Time `json:"completed"`
+	// Outputs is keyed by the name of each file written for the directory.
+	Outputs map[string]*OutputManifest `json:"outputs"`
+	// Unmatched is the number of files in the directory matching no route.
+	Unmatched int `json:"unmatched"`
+	// Rejected is the number of files in the directory which couldn't be read.
+	Rejected int `json:"rejected"`
+}
+
+type OutputManifest struct {
+	// Inputs is the number of files merged into the output.
+	Inputs int    `json:"inputs"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ReadManifest reads the manifest in outDir, or returns an empty one if merge
+// hasn't finished any directories there yet.
+func ReadManifest(outDir string) (*Manifest, error) {
+	manifest := &Manifest{Directories: make(map[string]*DirectoryManifest)}
+
+	manifestPath := filepath.Join(outDir, ManifestName)
+	bytes, err := os.ReadFile(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: reading %q: %w", ErrManifest, manifestPath, err)
+	}
+
+	err = json.Unmarshal(bytes, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing %q: %w", ErrManifest, manifestPath, err)
+	}
+	if manifest.Directories == nil {
+		manifest.Directories = make(map[string]*DirectoryManifest)
+	}
+
+	return manifest, nil
+}
+
+// Write replaces the manifest in outDir. The manifest is written to a
+// temporary file first so an interruption never leaves a partial manifest.
+func (m *Manifest) Write(outDir string) error {
+	bytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	manifestPath := filepath.Join(outDir, ManifestName)
+	file, err := createAtomic(manifestPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+	defer file.abort()
+
+	_, err = file.Write(bytes)
+	if err != nil {
+		return fmt.Errorf("%w: writing %q: %w", ErrManifest, file.Name(), err)
+	}
+
+	err = file.commit()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrManifest, err)
+	}
+
+	return nil
+}
+
+// Completed is whether the directory name was finished with every output in
+// outputNames, and its outputs in outDir are unchanged since. Outputs are
+// compared by size, or if verify also by checksum.
+func (m *Manifest) Completed(outDir, name string, outputNames []string, verify bool) (bool, error) {
+	directory, finished := m.Directories[name]
+	if !finished {
+		return false, nil
+	}
+
+	for _, outName := range outputNames {
+		if directory.Outputs[outName] == nil {
+			slog.Info("routes changed since directory was merged, merging again", "dir", name, "output", outName)
+			return false, nil
+		}
+	}
+
+	for outName, output := range directory.Outputs {
+		outPath := filepath.Join(outDir, outName)
+
+		stat, err := os.Stat(outPath)
+		if errors.Is(err, os.ErrNotExist) {
+			slog.Warn("output of finished directory is missing, merging again", "dir", name, "output", outPath)
+			return false, nil
+		} else if err != nil {
+			return false, fmt.Errorf("%w: stat %q: %w", ErrManifest, outPath, err)
+		}
+
+		if stat.Size() != output.Size {
+			slog.Warn("output of finished directory changed size, merging again", "dir", name, "output", outPath,
+				"size", stat.Size(), "want", output.Size)
+			return false, nil
+		}
+
+		if !verify {
+			continue
+		}
+
+		sum, err := fileSHA256(outPath)
+		if err != nil {
+			return false, err
+		}
+		if sum != output.SHA256 {
+			slog.Warn("output of finished directory changed checksum, merging again", "dir", name, "output", outPath)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: opening %q: %w", ErrManifest, path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", fmt.Errorf("%w: reading %q: %w", ErrManifest, path, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
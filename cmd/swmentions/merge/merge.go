@@ -0,0 +1,425 @@
+package merge
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/cli"
+	"github.com/willbeason/software-mentions/pkg/progress"
+	"github.com/willbeason/software-mentions/pkg/rejects"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+func init() {
+	Cmd.Flags().Bool("verify", false, "check the checksums of outputs of finished directories before skipping them")
+	Cmd.Flags().String("routes", "", "JSON file of routes from file name suffixes to output streams (default: one stream per extractor)")
+	Cmd.Flags().String("unmatched", "", "file to list the paths of files matching no route in")
+}
+
+var Cmd = cobra.Command{
+	Use:   "merge IN OUT",
+	Short: "Merge JSON files in directory into .jsonl.gz files",
+	Long: `Merge JSON files in directory into .jsonl.gz files.
+
+Each top-level directory of IN is merged into one file in OUT per output
+stream, with up to --workers directories merged at once. Files are routed to
+streams by the suffix of their names after the paper UUID. --routes takes a
+file such as
+
+  {"routes": [
+    {"suffix": "\\.software\\.json", "stream": "software"},
+    {"suffix": "\\.json", "stream": "papers", "codec": "zstd"}
+  ]}
+
+Files are sent to the first route they match, and codecs may be gzip, zstd,
+or none. Files matching no route are counted and may be listed with
+--unmatched. Files which aren't valid JSON objects are handled according to
+--on-error.
+
+Finished directories are recorded in OUT/` + ManifestName + ` along with the
+size and checksum of their outputs, so running merge again resumes an
+interrupted merge rather than starting over.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runE,
+}
+
+var ErrCompress = fmt.Errorf("compressing mentions")
+
+func runE(cmd *cobra.Command, args []string) error {
+	inDir := args[0]
+	outDir := args[1]
+
+	err := os.MkdirAll(outDir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	p, err := cli.NewProgress(cmd)
+	if err != nil {
+		return err
+	}
+
+	merger := &Merger{}
+
+	merger.Verify, err = cmd.Flags().GetBool("verify")
+	if err != nil {
+		return err
+	}
+
+	merger.Workers, err = cli.Workers(cmd)
+	if err != nil {
+		return err
+	}
+
+	routesPath, err := cmd.Flags().GetString("routes")
+	if err != nil {
+		return err
+	}
+	if routesPath != "" {
+		merger.Routes, err = ReadRoutes(routesPath)
+	} else {
+		merger.Routes, err = CompileRoutes(DefaultRoutes)
+	}
+	if err != nil {
+		return err
+	}
+
+	unmatchedPath, err := cmd.Flags().GetString("unmatched")
+	if err != nil {
+		return err
+	}
+	if unmatchedPath != "" {
+		unmatchedFile, err := os.Create(unmatchedPath)
+		if err != nil {
+			return fmt.Errorf("%w: creating %q: %w", ErrCompress, unmatchedPath, err)
+		}
+		defer func() {
+			err := unmatchedFile.Close()
+			if err != nil {
+				slog.Error("closing unmatched file list", "err", err)
+			}
+		}()
+
+		unmatchedWriter := bufio.NewWriter(unmatchedFile)
+		defer func() {
+			err := unmatchedWriter.Flush()
+			if err != nil {
+				slog.Error("flushing unmatched file list", "err", err)
+			}
+		}()
+		merger.Unmatched = unmatchedWriter
+	}
+
+	var closeRejects func()
+	merger.Rejects, closeRejects, err = cli.NewRejects(cmd, inDir)
+	if err != nil {
+		return err
+	}
+	defer closeRejects()
+
+	return merger.ProcessDir(p, inDir, outDir)
+}
+
+// Merger merges the JSON files for each paper into a few large files.
+type Merger struct {
+	// Routes decide which output each file is merged into. They must have been
+	// compiled with CompileRoutes.
+	Routes []Route
+
+	// Workers is the number of directories to merge at once.
+	Workers int
+
+	// Verify is whether to check the checksums of the outputs of finished
+	// directories, rather than just their sizes.
+	Verify bool
+
+	// Unmatched, if set, is written the path of each file matching no route,
+	// one per line.
+	Unmatched io.Writer
+
+	// Rejects decides what happens to files which can't be read. If nil, the
+	// merge fails.
+	Rejects *rejects.Handler
+}
+
+// ProcessDir merges each top-level directory of inDir into files in outDir.
+// Directories recorded as finished in outDir's manifest are skipped, so an
+// interrupted merge can be run again to resume it.
+func (m *Merger) ProcessDir(p *progress.Progress, inDir, outDir string) error {
+	entries, err := os.ReadDir(inDir)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := ReadManifest(outDir)
+	if err != nil {
+		return err
+	}
+
+	bar := p.AddBar(inDir, int64(len(entries)), progress.Files)
+
+	var todo []os.DirEntry
+	skipped := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			bar.Increment()
+			continue
+		}
+
+		completed, err := manifest.Completed(outDir, entry.Name(), m.outputNames(entry.Name()), m.Verify)
+		if err != nil {
+			return err
+		}
+		if completed {
+			skipped++
+			bar.Increment()
+			continue
+		}
+
+		todo = append(todo, entry)
+	}
+
+	if skipped > 0 {
+		slog.Info("skipped directories already merged", "dirs", skipped, "manifest", filepath.Join(outDir, ManifestName))
+	}
+
+	work := make(chan os.DirEntry)
+	results := make(chan entryResult)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(work)
+		for _, entry := range todo {
+			select {
+			case work <- entry:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for range m.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range work {
+				results <- m.processEntry(p, inDir, outDir, entry)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Only this goroutine updates the manifest and the list of unmatched
+	// files, so neither is written to concurrently.
+	unmatched := 0
+	var firstErr error
+	for result := range results {
+		bar.Increment()
+
+		// Keep draining results so workers can exit.
+		if firstErr != nil {
+			continue
+		}
+
+		err := result.err
+		if err == nil {
+			err = m.writeUnmatched(result.unmatched)
+		}
+		if err == nil {
+			unmatched += len(result.unmatched)
+			manifest.Directories[result.name] = result.directory
+			err = manifest.Write(outDir)
+		}
+		if err != nil {
+			firstErr = err
+			close(done)
+		}
+	}
+
+	if unmatched > 0 {
+		slog.Warn("files matched no route and were not merged", "files", unmatched)
+	}
+	if rejected := m.Rejects.Rejected(); rejected > 0 {
+		slog.Warn("files could not be read and were not merged", "files", rejected)
+	}
+
+	return firstErr
+}
+
+type entryResult struct {
+	name      string
+	directory *DirectoryManifest
+	// unmatched lists the paths of files matching no route.
+	unmatched []string
+	// rejected is the number of files which couldn't be read.
+	rejected int
+	err      error
+}
+
+// outputNames lists the files merged from directory dir.
+func (m *Merger) outputNames(dir string) []string {
+	names := make([]string, len(m.Routes))
+	for i, r := range m.Routes {
+		names[i] = r.OutputName(dir)
+	}
+	return names
+}
+
+func (m *Merger) writeUnmatched(paths []string) error {
+	if m.Unmatched == nil {
+		return nil
+	}
+
+	for _, path := range paths {
+		_, err := fmt.Fprintln(m.Unmatched, path)
+		if err != nil {
+			return fmt.Errorf("%w: listing unmatched files: %w", ErrCompress, err)
+		}
+	}
+
+	return nil
+}
+
+// processEntry merges the files in a top-level directory into one output per
+// route, walking the directory once. Outputs are only moved into place once
+// every one is complete.
+func (m *Merger) processEntry(p *progress.Progress, inDir, outDir string, entry os.DirEntry) entryResult {
+	result := entryResult{name: entry.Name()}
+
+	outputs := make([]*output, 0, len(m.Routes))
+	defer func() {
+		for _, out := range outputs {
+			out.abort()
+		}
+	}()
+
+	for _, r := range m.Routes {
+		out, err := createOutput(filepath.Join(outDir, r.OutputName(entry.Name())), r.Codec)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		outputs = append(outputs, out)
+	}
+
+	err := m.processDir(p, filepath.Join(inDir, entry.Name()), outputs, &result)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	directory := &DirectoryManifest{
+		Outputs:   make(map[string]*OutputManifest),
+		Unmatched: len(result.unmatched),
+		Rejected:  result.rejected,
+	}
+	for i, out := range outputs {
+		outputManifest, err := out.commit()
+		if err != nil {
+			result.err = err
+			return result
+		}
+		directory.Outputs[m.Routes[i].OutputName(entry.Name())] = outputManifest
+	}
+	directory.Completed = time.Now().UTC()
+	result.directory = directory
+
+	return result
+}
+
+// processDir writes each file in dir to the output of the first route it
+// matches, and adds files matching no route and rejected files to result.
+func (m *Merger) processDir(p *progress.Progress, dir string, outputs []*output, result *entryResult) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var bar *progress.Bar
+	if p != nil {
+		bar = p.AddBar(filepath.Base(dir), int64(len(entries)), progress.Files, progress.RemoveOnComplete)
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			err = m.processDir(nil, entryPath, outputs, result)
+			if err != nil {
+				return err
+			}
+		} else if i := matchRoute(m.Routes, entry.Name()); i >= 0 {
+			err = m.processFile(entryPath, outputs[i], result)
+			if err != nil {
+				return err
+			}
+		} else {
+			result.unmatched = append(result.unmatched, entryPath)
+		}
+
+		if bar != nil {
+			bar.Increment()
+		}
+	}
+
+	return nil
+}
+
+// readFile reads the JSON object in a per-paper file, noting the name of the
+// file it came from.
+func readFile(inPath string) (map[string]interface{}, error) {
+	inFile, err := os.Open(inPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func(inFile *os.File) {
+		_ = inFile.Close()
+	}(inFile)
+
+	var entry map[string]interface{}
+	err = json.NewDecoder(inFile).Decode(&entry)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, errors.New("got null but want a JSON object")
+	}
+
+	entry["file"] = filepath.Base(inPath)
+
+	return entry, nil
+}
+
+// processFile writes the file at inPath to out. Files which can't be read are
+// handled by the Rejects policy.
+func (m *Merger) processFile(inPath string, out *output, result *entryResult) error {
+	entry, err := readFile(inPath)
+	if err != nil {
+		err = m.Rejects.Handle(inPath, err)
+		if err != nil {
+			return fmt.Errorf("%w: reading %q: %w", ErrCompress, inPath, err)
+		}
+		result.rejected++
+		return nil
+	}
+
+	// The Encoder automatically writes a newline after each JSON object.
+	err = json.NewEncoder(out).Encode(entry)
+	if err != nil {
+		return fmt.Errorf("%w: writing %q: %w", ErrCompress, out.file.path, err)
+	}
+	out.inputs++
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package merge
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// tmpSuffix is appended to the names of files until they are complete.
+const tmpSuffix = ".tmp"
+
+// atomicFile is written under a temporary name and only renamed into place
+// once complete, so an interrupted merge never leaves a partial file that
+// looks finished.
+type atomicFile struct {
+	*os.File
+	path string
+	// done is whether the file was committed or aborted.
+	done bool
+}
+
+func createAtomic(path string) (*atomicFile, error) {
+	file, err := os.Create(path + tmpSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("creating %q: %w", path+tmpSuffix, err)
+	}
+
+	return &atomicFile{File: file, path: path}, nil
+}
+
+// commit syncs the file to disk and renames it into place.
+func (f *atomicFile) commit() error {
+	f.done = true
+
+	err := f.Sync()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("syncing %q: %w", f.Name(), err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("closing %q: %w", f.Name(), err)
+	}
+
+	err = os.Rename(f.Name(), f.path)
+	if err != nil {
+		return fmt.Errorf("renaming %q: %w", f.Name(), err)
+	}
+
+	return nil
+}
+
+// abort removes the temporary file unless it was committed. It is safe to
+// defer abort immediately after creating the file.
+func (f *atomicFile) abort() {
+	if f.done {
+		return
+	}
+	f.done = true
+
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// output is a compressed .jsonl file merged from many input files.
+type output struct {
+	file       *atomicFile
+	hash       hash.Hash
+	compressor io.WriteCloser
+	writer     *bufio.Writer
+
+	// inputs is the number of files merged into the output.
+	inputs int
+}
+
+func createOutput(path string, codec Codec) (*output, error) {
+	file, err := createAtomic(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+
+	// The checksum is of the compressed file as written to disk.
+	hash := sha256.New()
+	compressor, err := codec.NewWriter(io.MultiWriter(file, hash))
+	if err != nil {
+		file.abort()
+		return nil, fmt.Errorf("%w: starting %s stream for %q: %w", ErrCompress, codec, path, err)
+	}
+
+	return &output{
+		file:       file,
+		hash:       hash,
+		compressor: compressor,
+		writer:     bufio.NewWriter(compressor),
+	}, nil
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	return o.writer.Write(p)
+}
+
+// commit finishes writing the output, moves it into place, and describes it
+// for the manifest.
+func (o *output) commit() (*OutputManifest, error) {
+	err := o.writer.Flush()
+	if err != nil {
+		o.file.abort()
+		return nil, fmt.Errorf("%w: writing %q: %w", ErrCompress, o.file.Name(), err)
+	}
+
+	err = o.compressor.Close()
+	if err != nil {
+		o.file.abort()
+		return nil, fmt.Errorf("%w: writing %q: %w", ErrCompress, o.file.Name(), err)
+	}
+
+	stat, err := o.file.Stat()
+	if err != nil {
+		o.file.abort()
+		return nil, fmt.Errorf("%w: stat %q: %w", ErrCompress, o.file.Name(), err)
+	}
+
+	err = o.file.commit()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompress, err)
+	}
+
+	return &OutputManifest{
+		Inputs: o.inputs,
+		Size:   stat.Size(),
+		SHA256: hex.EncodeToString(o.hash.Sum(nil)),
+	}, nil
+}
+
+func (o *output) abort() {
+	o.file.abort()
+}
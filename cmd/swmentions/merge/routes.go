@@ -0,0 +1,203 @@
+package merge
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const UUIDPattern = `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+
+const (
+	PaperSuffix    = `\.json`
+	SoftwareSuffix = `\.software\.json`
+)
+
+// SuffixPattern matches the names of files for a paper whose names end in
+// suffix, a regular expression.
+func SuffixPattern(suffix string) *regexp.Regexp {
+	return regexp.MustCompile(suffixExpr(suffix))
+}
+
+func suffixExpr(suffix string) string {
+	return UUIDPattern + `(?:` + suffix + `)$`
+}
+
+var ErrRoutes = errors.New("reading merge routes")
+
+// Codec is how an output stream is compressed.
+type Codec string
+
+const (
+	CodecGzip Codec = "gzip"
+	CodecZstd Codec = "zstd"
+	CodecNone Codec = "none"
+)
+
+// Ext is the extension of files compressed with c.
+func (c Codec) Ext() string {
+	switch c {
+	case CodecZstd:
+		return ".zst"
+	case CodecNone:
+		return ""
+	default:
+		return ".gz"
+	}
+}
+
+// NewWriter compresses what is written to w.
+func (c Codec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	switch c {
+	case CodecZstd:
+		return zstd.NewWriter(w)
+	case CodecNone:
+		return nopCloser{w}, nil
+	default:
+		return gzip.NewWriter(w), nil
+	}
+}
+
+// NewReader decompresses what is read from r.
+func (c Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	switch c {
+	case CodecZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case CodecNone:
+		return io.NopCloser(r), nil
+	default:
+		return gzip.NewReader(r)
+	}
+}
+
+// CodecOf is the codec of an output file, from the extension of its name.
+func CodecOf(name string) Codec {
+	switch {
+	case strings.HasSuffix(name, CodecGzip.Ext()):
+		return CodecGzip
+	case strings.HasSuffix(name, CodecZstd.Ext()):
+		return CodecZstd
+	default:
+		return CodecNone
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// Route sends the files of a paper whose names end in Suffix to the output
+// stream Stream.
+type Route struct {
+	// Suffix is a regular expression matching the rest of a file's name after
+	// the paper UUID, such as `\.jats\.software\.json`.
+	Suffix string `json:"suffix"`
+	// Stream names the output, which for directory DIR is written to
+	// DIR.STREAM.jsonl followed by the codec's extension.
+	Stream string `json:"stream"`
+	// Codec defaults to gzip.
+	Codec Codec `json:"codec,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// DefaultRoutes merge the outputs of each software mention extractor
+// separately.
+var DefaultRoutes = []Route{
+	{Suffix: `\.jats` + SoftwareSuffix, Stream: "jats.software"},
+	{Suffix: `\.pub2tei\.tei` + PaperSuffix, Stream: "pub2tei.tei"},
+	{Suffix: `\.latex\.tei` + SoftwareSuffix, Stream: "latex.tei.software"},
+	{Suffix: `\.grobid\.tei` + SoftwareSuffix, Stream: "grobid.tei.software"},
+}
+
+// RoutesConfig is the format of the file passed to --routes. Files are sent
+// to the first route they match.
+type RoutesConfig struct {
+	Routes []Route `json:"routes"`
+}
+
+// ReadRoutes reads and validates the routes in a RoutesConfig file.
+func ReadRoutes(path string) ([]Route, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRoutes, err)
+	}
+
+	config := &RoutesConfig{}
+	err = json.Unmarshal(bytes, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing %q: %w", ErrRoutes, path, err)
+	}
+
+	return CompileRoutes(config.Routes)
+}
+
+// CompileRoutes validates routes and prepares them for matching file names.
+func CompileRoutes(routes []Route) ([]Route, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("%w: no routes", ErrRoutes)
+	}
+
+	compiled := make([]Route, len(routes))
+	streams := make(map[string]bool)
+	for i, r := range routes {
+		switch {
+		case r.Stream == "":
+			return nil, fmt.Errorf("%w: route %d has no stream", ErrRoutes, i)
+		case strings.ContainsAny(r.Stream, `/\`):
+			return nil, fmt.Errorf("%w: stream %q must not contain path separators", ErrRoutes, r.Stream)
+		case streams[r.Stream]:
+			return nil, fmt.Errorf("%w: more than one route writes to stream %q", ErrRoutes, r.Stream)
+		}
+		streams[r.Stream] = true
+
+		switch r.Codec {
+		case "":
+			r.Codec = CodecGzip
+		case CodecGzip, CodecZstd, CodecNone:
+		default:
+			return nil, fmt.Errorf("%w: codec of stream %q must be %s, %s, or %s, not %q", ErrRoutes, r.Stream, CodecGzip, CodecZstd, CodecNone, r.Codec)
+		}
+
+		pattern, err := regexp.Compile(suffixExpr(r.Suffix))
+		if err != nil {
+			return nil, fmt.Errorf("%w: suffix of stream %q: %w", ErrRoutes, r.Stream, err)
+		}
+		r.pattern = pattern
+
+		compiled[i] = r
+	}
+
+	return compiled, nil
+}
+
+// OutputName is the name of the file the route writes files in directory dir
+// to.
+func (r *Route) OutputName(dir string) string {
+	return dir + "." + r.Stream + ".jsonl" + r.Codec.Ext()
+}
+
+// matchRoute returns the index of the first route matching the file name, or
+// -1 if there is none.
+func matchRoute(routes []Route, name string) int {
+	for i := range routes {
+		if routes[i].pattern.MatchString(name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,303 @@
+package rmprocessed
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/cmd/swmentions/merge"
+	"github.com/willbeason/software-mentions/pkg/cli"
+	"github.com/willbeason/software-mentions/pkg/progress"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// PaperFilePattern matches the names of the files written for each paper,
+// which are the ones rm-processed may remove.
+var PaperFilePattern = merge.SuffixPattern(`\..+`)
+
+func init() {
+	Cmd.Flags().Bool("dry-run", false, "print the files which would be removed instead of removing them")
+}
+
+var Cmd = cobra.Command{
+	Use:   "rm-processed IN MERGED",
+	Short: "Remove already-processed JSON files",
+	Long: `Remove already-processed JSON files.
+
+MERGED is the output directory of a merge of IN. A file for a paper in IN is
+only removed if it was merged into one of the outputs recorded in
+MERGED/` + merge.ManifestName + `. Files in directories which haven't finished
+merging, and files which were unmatched or rejected by merge, are kept and
+counted, and rm-processed fails if there were any.
+
+Directories left empty are removed. With --dry-run, the files which would be
+removed are printed instead, followed by totals.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runE,
+}
+
+var ErrRm = fmt.Errorf("removing processed files")
+
+func runE(cmd *cobra.Command, args []string) error {
+	inDir := args[0]
+	mergedDir := args[1]
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	var p *progress.Progress
+	if dryRun {
+		// The files which would be removed are listed on stdout, so don't draw
+		// progress bars over them.
+		p = progress.New(true, progress.DefaultInterval)
+	} else {
+		p, err = cli.NewProgress(cmd)
+		if err != nil {
+			return err
+		}
+	}
+
+	out := bufio.NewWriter(cmd.OutOrStdout())
+	defer func() {
+		err := out.Flush()
+		if err != nil {
+			slog.Error("flushing list of files", "err", err)
+		}
+	}()
+
+	remover := &Remover{
+		MergedDir: mergedDir,
+		DryRun:    dryRun,
+		Out:       out,
+	}
+
+	return remover.ProcessDir(p, inDir)
+}
+
+// Remover removes the files for each paper once they have been merged.
+type Remover struct {
+	// MergedDir is the output directory of merge. Only files merged into the
+	// outputs recorded in its manifest are removed.
+	MergedDir string
+
+	// DryRun is whether to list the files which would be removed on Out rather
+	// than removing them.
+	DryRun bool
+	Out    io.Writer
+
+	removed int
+	bytes   int64
+	kept    int
+}
+
+// ProcessDir removes the files in each top-level directory of inDir which were
+// merged into MergedDir.
+func (r *Remover) ProcessDir(p *progress.Progress, inDir string) error {
+	entries, err := os.ReadDir(inDir)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := merge.ReadManifest(r.MergedDir)
+	if err != nil {
+		return err
+	}
+
+	bar := p.AddBar(inDir, int64(len(entries)), progress.Files)
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			bar.Increment()
+			continue
+		}
+
+		merged, err := r.mergedFiles(manifest, entry.Name())
+		if err != nil {
+			return err
+		}
+
+		kept := r.kept
+		err = r.processEntry(p, filepath.Join(inDir, entry.Name()), merged)
+		if err != nil {
+			return err
+		}
+		if kept = r.kept - kept; kept > 0 {
+			slog.Warn("keeping files not found in merged outputs", "dir", entry.Name(), "files", kept)
+		}
+
+		bar.Increment()
+	}
+
+	p.Wait()
+
+	if r.DryRun {
+		_, err = fmt.Fprintf(r.Out, "would remove %d files (%d bytes), keeping %d not found in merged outputs\n", r.removed, r.bytes, r.kept)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRm, err)
+		}
+		return nil
+	}
+
+	slog.Info("removed merged files", "files", r.removed, "bytes", r.bytes)
+	if r.kept > 0 {
+		return fmt.Errorf("%w: kept %d files not found in the merged outputs in %q", ErrRm, r.kept, r.MergedDir)
+	}
+
+	return nil
+}
+
+// mergedFiles returns the names of the files merged from the top-level
+// directory name, or nil if it hasn't finished merging. Each output is read in
+// full and checked against the number of files the manifest says were merged
+// into it, so files are never removed on the strength of a truncated output.
+func (r *Remover) mergedFiles(manifest *merge.Manifest, name string) (map[string]bool, error) {
+	directory := manifest.Directories[name]
+	if directory == nil {
+		slog.Info("directory has not been merged", "dir", name)
+		return nil, nil
+	}
+
+	merged := make(map[string]bool)
+	for outName, output := range directory.Outputs {
+		outPath := filepath.Join(r.MergedDir, outName)
+
+		records, err := readMergedFiles(outPath, merged)
+		if err != nil {
+			return nil, err
+		}
+		if records != output.Inputs {
+			return nil, fmt.Errorf("%w: %q has %d records but the manifest says %d files were merged into it",
+				ErrRm, outPath, records, output.Inputs)
+		}
+	}
+
+	return merged, nil
+}
+
+// readMergedFiles adds the name of the file each record of the merge output
+// at path came from to merged, and returns the number of records.
+func readMergedFiles(path string, merged map[string]bool) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("%w: opening merged output: %w", ErrRm, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader, err := merge.CodecOf(path).NewReader(bufio.NewReader(file))
+	if err != nil {
+		return 0, fmt.Errorf("%w: reading %q: %w", ErrRm, path, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	decoder := json.NewDecoder(reader)
+	records := 0
+	for {
+		var record struct {
+			File string `json:"file"`
+		}
+		err = decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		} else if err != nil {
+			return 0, fmt.Errorf("%w: reading record %d of %q: %w", ErrRm, records+1, path, err)
+		}
+
+		merged[record.File] = true
+		records++
+	}
+}
+
+// processEntry removes the files under dir whose names are in merged, and then
+// dir itself if it is left empty.
+func (r *Remover) processEntry(p *progress.Progress, dir string, merged map[string]bool) error {
+	beforeEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var bar *progress.Bar
+	if p != nil {
+		bar = p.AddBar(filepath.Base(dir), int64(len(beforeEntries)), progress.Files, progress.RemoveOnComplete)
+	}
+
+	for _, beforeEntry := range beforeEntries {
+		beforeEntryPath := filepath.Join(dir, beforeEntry.Name())
+		if beforeEntry.IsDir() {
+			err = r.processEntry(nil, beforeEntryPath, merged)
+		} else {
+			err = r.processFile(beforeEntryPath, beforeEntry, merged)
+		}
+		if err != nil {
+			return err
+		}
+
+		if bar != nil {
+			bar.Increment()
+		}
+	}
+
+	if r.DryRun {
+		return nil
+	}
+
+	afterEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	if len(afterEntries) == 0 {
+		err = os.Remove(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processFile removes the file at path if it is a paper's file which was
+// merged. Other files for papers are kept and counted.
+func (r *Remover) processFile(path string, entry os.DirEntry, merged map[string]bool) error {
+	name := entry.Name()
+	if !PaperFilePattern.MatchString(name) {
+		return nil
+	}
+
+	if !merged[name] {
+		slog.Debug("keeping file not found in merged outputs", "path", path)
+		r.kept++
+		return nil
+	}
+
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+
+	if r.DryRun {
+		_, err = fmt.Fprintln(r.Out, path)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRm, err)
+		}
+	} else {
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.removed++
+	r.bytes += info.Size()
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package sortjsonl
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/bondsmith/jsonio"
+	"github.com/willbeason/software-mentions/pkg/jsonl"
+	"io"
+	"iter"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Cmd.Flags().String("max-memory", "", "sort using temporary files once buffered entries exceed this size, e.g. 4GiB; at most 128 files are merged at once, each holding one entry in memory (default: sort in memory)")
+	Cmd.Flags().String("temp-dir", "", "directory for temporary files (default: the system temporary directory)")
+	Cmd.Flags().String("key", jsonl.DefaultKeyPath, "dotted path of the field to sort by, e.g. metadata.id")
+	Cmd.Flags().String("key-type", string(jsonl.KeyUUID), "how to compare keys: uuid, string, or number")
+	Cmd.Flags().String("dedupe", string(jsonl.DedupeNone), "what to do with entries sharing a key: none, first, last, or error")
+}
+
+var Cmd = cobra.Command{
+	Use:   "sort FILE",
+	Short: "sort entries in a JSONL file by a key, by default their UUID",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runE,
+}
+
+func runE(cmd *cobra.Command, args []string) error {
+	inPath := args[0]
+
+	maxMemoryString, err := cmd.Flags().GetString("max-memory")
+	if err != nil {
+		return err
+	}
+
+	maxMemory, err := parseBytes(maxMemoryString)
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := cmd.Flags().GetString("temp-dir")
+	if err != nil {
+		return err
+	}
+
+	keyPath, err := cmd.Flags().GetString("key")
+	if err != nil {
+		return err
+	}
+
+	keyType, err := cmd.Flags().GetString("key-type")
+	if err != nil {
+		return err
+	}
+	switch jsonl.KeyType(keyType) {
+	case jsonl.KeyUUID, jsonl.KeyString, jsonl.KeyNumber:
+	default:
+		return fmt.Errorf("unknown --key-type %q, want uuid, string, or number", keyType)
+	}
+
+	dedupe, err := cmd.Flags().GetString("dedupe")
+	if err != nil {
+		return err
+	}
+	switch jsonl.DedupePolicy(dedupe) {
+	case jsonl.DedupeNone, jsonl.DedupeFirst, jsonl.DedupeLast, jsonl.DedupeError:
+	default:
+		return fmt.Errorf("unknown --dedupe %q, want none, first, last, or error", dedupe)
+	}
+
+	sorter := &jsonl.Sorter{
+		KeyPath:   keyPath,
+		KeyType:   jsonl.KeyType(keyType),
+		Dedupe:    jsonl.DedupePolicy(dedupe),
+		MaxMemory: maxMemory,
+		TempDir:   tempDir,
+	}
+
+	err = sortFile(inPath, sorter)
+	if err != nil {
+		return err
+	}
+
+	if sorter.Collisions() > 0 {
+		fmt.Printf("found %d entries with duplicate keys\n", sorter.Collisions())
+	}
+
+	return nil
+}
+
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	// Longer suffixes first so "GiB" isn't read as "B".
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"B", 1},
+}
+
+// parseBytes parses sizes like "512MiB" or "4G". An empty string is zero.
+func parseBytes(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSuffix(s, u.suffix)
+			unit = u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing size %q: %w", s, err)
+	}
+
+	return n * unit, nil
+}
+
+func sortFile(inPath string, sorter *jsonl.Sorter) error {
+	file, err := os.Open(inPath)
+	if err != nil {
+		return fmt.Errorf("opening %q: %w", inPath, err)
+	}
+
+	var reader io.Reader = file
+	if strings.HasSuffix(inPath, ".gz") {
+		reader, err = gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("starting gzip reader stream for %q: %w", inPath, err)
+		}
+	}
+
+	entries := jsonio.NewReader(reader, func() *map[string]any {
+		v := make(map[string]any)
+		return &v
+	})
+
+	sorted, err := sorter.Sort(entries.Read())
+	if err != nil {
+		return err
+	}
+
+	outPath := inPath + ".new"
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+
+	err = writeSorted(outFile, sorted, sorter)
+	closeErr := outFile.Close()
+	if err == nil && closeErr != nil {
+		err = fmt.Errorf("closing %q: %w", outPath, closeErr)
+	}
+	if err != nil {
+		// The sort stops early on errors such as duplicate keys, so remove the
+		// partial output rather than leave it looking complete.
+		removeErr := os.Remove(outPath)
+		if removeErr != nil {
+			slog.Error("removing partial output file", "path", outPath, "err", removeErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// writeSorted writes the sorted entries to w as gzipped JSONL. Returns the
+// sorter's error if the sort ended early.
+func writeSorted(w io.Writer, sorted iter.Seq[map[string]any], sorter *jsonl.Sorter) error {
+	writer := gzip.NewWriter(w)
+	jsonWriter := jsonio.NewWriter(writer, sorted)
+	err := jsonWriter.Write()
+	if err != nil {
+		_ = writer.Close()
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("closing gzip writer: %w", err)
+	}
+
+	return sorter.Err()
+}
@@ -0,0 +1,586 @@
+package stats
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	bondsmith "github.com/willbeason/bondsmith"
+	"github.com/willbeason/bondsmith/jsonio"
+	"github.com/willbeason/software-mentions/pkg/cli"
+	"github.com/willbeason/software-mentions/pkg/jsonl"
+	"github.com/willbeason/software-mentions/pkg/progress"
+	"github.com/willbeason/software-mentions/pkg/rejects"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const IncEvery = 1 << 10
+
+func init() {
+	Cmd.Flags().String("out", "", "output file path (default: stdout)")
+	Cmd.Flags().String("format", formatText, "output format: text, json (one object per line), csv, or a draft schema as proto or arrow")
+	Cmd.Flags().String("name", "Record", "name of the top-level message or schema for --format proto and arrow")
+	Cmd.Flags().StringSlice("include", nil, "in directory mode, analyze files whose names match these globs, e.g. *.software.json")
+	Cmd.Flags().StringSlice("exclude", nil, "in directory mode, skip files and directories whose names match these globs")
+	Cmd.Flags().StringSlice("include-regex", nil, "in directory mode, analyze files whose paths relative to DIR match these regular expressions (default if no includes: "+defaultInclude+")")
+	Cmd.Flags().StringSlice("exclude-regex", nil, "in directory mode, skip files and directories whose paths relative to DIR match these regular expressions")
+}
+
+var Cmd = cobra.Command{
+	Use:   "stats FILE|DIR",
+	Short: "Collect statistics about keys and values in .json and .jsonl files",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runE,
+}
+
+var ErrJsonStats = errors.New("getting JSON statistics")
+
+const (
+	formatText = "text"
+	formatJson = "json"
+	formatCsv  = "csv"
+
+	formatProto = "proto"
+	formatArrow = "arrow"
+)
+
+// defaultInclude matches the shards written by merge, but not the partial
+// files an interrupted merge leaves behind.
+const defaultInclude = `(^|/)[0-9a-f]{2}\.software\.jsonl\.gz$`
+
+// isJsonFile is whether inPath has an extension stats can read. .jsonl
+// files hold one object per line, and .json files hold one or more
+// whitespace-separated values, such as the per-paper files merge reads.
+func isJsonFile(inPath string) bool {
+	inPath = strings.TrimSuffix(inPath, ".gz")
+	return strings.HasSuffix(inPath, ".jsonl") || strings.HasSuffix(inPath, ".json")
+}
+
+func runE(cmd *cobra.Command, args []string) error {
+	inPath := args[0]
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case formatText, formatJson, formatCsv, formatProto, formatArrow:
+	default:
+		return fmt.Errorf("%w: --format must be %s, %s, %s, %s, or %s, not %q", ErrJsonStats, formatText, formatJson, formatCsv, formatProto, formatArrow, format)
+	}
+
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return err
+	}
+
+	workers, err := cli.Workers(cmd)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Stat(inPath)
+	if err != nil {
+		return fmt.Errorf("%w: stat %q: %w", ErrJsonStats, inPath, err)
+	}
+
+	p, err := cli.NewProgress(cmd)
+	if err != nil {
+		return err
+	}
+
+	var inPaths []string
+	if f.IsDir() {
+		matcher, err := newFileMatcher(cmd)
+		if err != nil {
+			return err
+		}
+		inPaths, err = collectFiles(inPath, matcher)
+		if err != nil {
+			return err
+		}
+	} else if isJsonFile(inPath) {
+		inPaths = []string{inPath}
+	} else {
+		return fmt.Errorf("%w: file %q is neither a directory nor a .json or .jsonl file", ErrJsonStats, inPath)
+	}
+
+	handler, closeRejects, err := cli.NewRejects(cmd, inPath)
+	if err != nil {
+		return err
+	}
+	defer closeRejects()
+
+	keyValueSets, err := analyzeFiles(p, inPaths, workers, handler)
+	if err != nil {
+		return err
+	}
+	if rejected := handler.Rejected(); rejected > 0 {
+		slog.Warn("files could not be read and were left out of the statistics", "files", rejected)
+	}
+
+	paths := make([]string, len(keyValueSets))
+	i := 0
+	for path := range keyValueSets {
+		paths[i] = path
+		i++
+	}
+	sort.Strings(paths)
+
+	outPath, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return err
+	}
+
+	outFile := os.Stdout
+	if outPath != "" {
+		outFile, err = os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err := outFile.Close()
+			if err != nil {
+				slog.Error("closing output file", "err", err)
+			}
+		}()
+	}
+
+	switch format {
+	case formatJson:
+		err = writeJson(outFile, paths, keyValueSets)
+	case formatCsv:
+		err = writeCsv(outFile, paths, keyValueSets)
+	case formatProto, formatArrow:
+		err = writeSchema(outFile, format, name, keyValueSets)
+	default:
+		err = writeText(outFile, paths, keyValueSets)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: writing output: %w", ErrJsonStats, err)
+	}
+
+	return nil
+}
+
+func writeText(w io.Writer, paths []string, keyValueSets map[string]jsonl.Field) error {
+	for _, path := range paths {
+		_, err := fmt.Fprintf(w, "%s;%s\n", path, keyValueSets[path])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pathSummary is the JSON output for a single field.
+type pathSummary struct {
+	Path string `json:"path"`
+	jsonl.Summary
+}
+
+func writeJson(w io.Writer, paths []string, keyValueSets map[string]jsonl.Field) error {
+	encoder := json.NewEncoder(w)
+	for _, path := range paths {
+		err := encoder.Encode(pathSummary{
+			Path:    path,
+			Summary: keyValueSets[path].Summary(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCsv writes one row per field. Histograms are written as JSON objects so
+// that values containing delimiters are unambiguous.
+func writeCsv(w io.Writer, paths []string, keyValueSets map[string]jsonl.Field) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"path", "type", "count", "nulls", "min", "max", "values", "keys", "lengths",
+		"distinct", "quantiles", "top", "min_length", "max_length", "mean_length"})
+	if err != nil {
+		return err
+	}
+
+	formatNumber := func(f *float64) string {
+		if f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*f, 'f', -1, 64)
+	}
+	formatInt := func(i *int) string {
+		if i == nil {
+			return ""
+		}
+		return strconv.Itoa(*i)
+	}
+
+	for _, path := range paths {
+		summary := keyValueSets[path].Summary()
+
+		histograms := make([]string, 5)
+		for i, histogram := range []any{summary.Values, summary.Keys, summary.Lengths, summary.Quantiles, summary.Top} {
+			histogramJson, err := json.Marshal(histogram)
+			if err != nil {
+				return err
+			}
+
+			// Leave absent histograms empty rather than "null".
+			if string(histogramJson) != "null" {
+				histograms[i] = string(histogramJson)
+			}
+		}
+
+		err = writer.Write([]string{
+			path,
+			summary.Type,
+			strconv.Itoa(summary.Count),
+			strconv.Itoa(summary.Nulls),
+			formatNumber(summary.Min),
+			formatNumber(summary.Max),
+			histograms[0],
+			histograms[1],
+			histograms[2],
+			strconv.FormatUint(summary.Distinct, 10),
+			histograms[3],
+			histograms[4],
+			formatInt(summary.MinLength),
+			formatInt(summary.MaxLength),
+			formatNumber(summary.MeanLength),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeSchema writes a draft schema inferred from the fields seen, either as a
+// .proto file or as Go source declaring an Arrow schema.
+func writeSchema(w io.Writer, format, name string, keyValueSets map[string]jsonl.Field) error {
+	tree, err := jsonl.NewSchemaTree(keyValueSets)
+	if err != nil {
+		return err
+	}
+
+	var schema string
+	if format == formatProto {
+		schema = tree.Proto(name)
+	} else {
+		schema = jsonl.ArrowGo(name, tree.ArrowSchema())
+	}
+
+	_, err = io.WriteString(w, schema)
+	return err
+}
+
+// fileMatcher decides which files beneath a directory to analyze. Globs match
+// file names, and regular expressions match slash-separated paths relative to
+// the directory.
+type fileMatcher struct {
+	include      []string
+	exclude      []string
+	includeRegex []*regexp.Regexp
+	excludeRegex []*regexp.Regexp
+}
+
+func newFileMatcher(cmd *cobra.Command) (*fileMatcher, error) {
+	m := &fileMatcher{}
+
+	var err error
+	for name, globs := range map[string]*[]string{"include": &m.include, "exclude": &m.exclude} {
+		*globs, err = cmd.Flags().GetStringSlice(name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, glob := range *globs {
+			_, err = filepath.Match(glob, "")
+			if err != nil {
+				return nil, fmt.Errorf("%w: parsing --%s glob %q: %w", ErrJsonStats, name, glob, err)
+			}
+		}
+	}
+
+	for name, regexes := range map[string]*[]*regexp.Regexp{"include-regex": &m.includeRegex, "exclude-regex": &m.excludeRegex} {
+		patterns, err := cmd.Flags().GetStringSlice(name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pattern := range patterns {
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%w: compiling --%s pattern %q: %w", ErrJsonStats, name, pattern, err)
+			}
+			*regexes = append(*regexes, regex)
+		}
+	}
+
+	if len(m.include) == 0 && len(m.includeRegex) == 0 {
+		m.includeRegex = []*regexp.Regexp{regexp.MustCompile(defaultInclude)}
+	}
+
+	return m, nil
+}
+
+func (m *fileMatcher) matches(globs []string, regexes []*regexp.Regexp, relPath string) bool {
+	name := path.Base(relPath)
+	for _, glob := range globs {
+		// Patterns were validated in newFileMatcher.
+		if matched, _ := filepath.Match(glob, name); matched {
+			return true
+		}
+	}
+
+	for _, regex := range regexes {
+		if regex.MatchString(relPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *fileMatcher) excluded(relPath string) bool {
+	return m.matches(m.exclude, m.excludeRegex, relPath)
+}
+
+func (m *fileMatcher) included(relPath string) bool {
+	return m.matches(m.include, m.includeRegex, relPath)
+}
+
+// collectFiles lists the .json and .jsonl files beneath inPath which matcher
+// includes, in sorted order. Every directory is descended into unless it is
+// excluded.
+func collectFiles(inPath string, matcher *fileMatcher) ([]string, error) {
+	var result []string
+
+	err := filepath.WalkDir(inPath, func(entryPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("%w: reading %q: %w", ErrJsonStats, entryPath, err)
+		}
+		if entryPath == inPath {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(inPath, entryPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrJsonStats, err)
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if matcher.excluded(relPath) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() || !isJsonFile(entryPath) || !matcher.included(relPath) {
+			return nil
+		}
+		result = append(result, entryPath)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type fileResult struct {
+	index        int
+	keyValueSets map[string]jsonl.Field
+	err          error
+}
+
+// analyzeFiles collects the fields of each file on a pool of workers and
+// merges them in the order of inPaths. Approximate statistics such as
+// quantiles depend on the order values are merged in, so merging in a fixed
+// order means the result does not depend on the number of workers. Files
+// which can't be read are left out or fail the analysis as handler decides.
+func analyzeFiles(p *progress.Progress, inPaths []string, workers int, handler *rejects.Handler) (map[string]jsonl.Field, error) {
+	bar := p.AddBar("files", int64(len(inPaths)), progress.Files)
+
+	work := make(chan int)
+	results := make(chan fileResult)
+	done := make(chan struct{})
+	// window limits how far workers read ahead of the next file to merge, so
+	// one slow file can't leave the fields of every later file waiting in
+	// memory.
+	window := make(chan struct{}, 2*workers)
+
+	go func() {
+		defer close(work)
+		for i := range inPaths {
+			select {
+			case window <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			select {
+			case work <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				keyValueSets := make(map[string]jsonl.Field)
+				err := processJsonFile(p, inPaths[i], keyValueSets)
+				if err != nil {
+					// Leave out everything read from a rejected file.
+					keyValueSets = make(map[string]jsonl.Field)
+					err = handler.Handle(inPaths[i], err)
+				}
+				results <- fileResult{index: i, keyValueSets: keyValueSets, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	merged := make(map[string]jsonl.Field)
+	// pending holds finished files until every file before them is merged.
+	pending := make(map[int]map[string]jsonl.Field)
+	next := 0
+	var firstErr error
+	for result := range results {
+		bar.Increment()
+
+		// Keep draining results so workers can exit.
+		if firstErr != nil {
+			continue
+		}
+
+		err := result.err
+		if err == nil {
+			pending[result.index] = result.keyValueSets
+			for pending[next] != nil && err == nil {
+				err = jsonl.MergeFields(merged, pending[next])
+				delete(pending, next)
+				next++
+				<-window
+			}
+		}
+		if err != nil {
+			firstErr = err
+			close(done)
+		}
+	}
+
+	return merged, firstErr
+}
+
+func processJsonFile(p *progress.Progress, inPath string, keyValueSets map[string]jsonl.Field) error {
+	file, err := os.Open(inPath)
+	if err != nil {
+		return fmt.Errorf("%w: opening %q: %w", ErrJsonStats, inPath, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	stat, err := os.Stat(inPath)
+	if err != nil {
+		return fmt.Errorf("%w: getting stat for %q: %w", ErrJsonStats, inPath, err)
+	}
+
+	countReader := bondsmith.NewCountReader(file)
+	var reader io.Reader = countReader
+	if strings.HasSuffix(inPath, ".gz") {
+		reader, err = gzip.NewReader(countReader)
+		if err != nil {
+			return fmt.Errorf("%w: starting gzip reader stream for %q: %w", ErrJsonStats, inPath, err)
+		}
+	}
+
+	if !strings.HasSuffix(strings.TrimSuffix(inPath, ".gz"), ".jsonl") {
+		return processJsonValues(inPath, reader, keyValueSets)
+	}
+
+	entries := jsonio.NewReader(reader, func() *map[string]any {
+		v := make(map[string]any)
+		return &v
+	})
+
+	bar := p.AddBar(filepath.Base(inPath), stat.Size(), progress.Bytes, progress.RemoveOnComplete)
+
+	i := 0
+	lastSeen := 0
+	for entry, err := range entries.Read() {
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+
+		err = jsonl.AddFields("", *entry, keyValueSets)
+		if err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrJsonStats, inPath, err)
+		}
+
+		i++
+		if i%IncEvery == 0 {
+			curProgress := int(countReader.Count())
+			bar.IncrBy(curProgress - lastSeen)
+
+			lastSeen = curProgress
+		}
+	}
+	bar.IncrBy(int(countReader.Count()) - lastSeen)
+
+	return nil
+}
+
+// processJsonValues analyzes each JSON value in a .json file. These files are
+// small, so they get no progress bar of their own.
+func processJsonValues(inPath string, reader io.Reader, keyValueSets map[string]jsonl.Field) error {
+	decoder := json.NewDecoder(reader)
+	for {
+		var value any
+		err := decoder.Decode(&value)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("%w: decoding %q: %w", ErrJsonStats, inPath, err)
+		}
+
+		err = jsonl.AddFields("", value, keyValueSets)
+		if err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrJsonStats, inPath, err)
+		}
+	}
+}
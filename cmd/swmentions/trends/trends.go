@@ -0,0 +1,363 @@
+package trends
+
+import (
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/arrow/memory"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/comentions"
+	"github.com/willbeason/software-mentions/pkg/tables"
+	"log/slog"
+	"path/filepath"
+	"sort"
+)
+
+func init() {
+	Cmd.Flags().String("papers", "", "papers table written by extract papers (default: DIR/"+tables.Papers+tables.ParquetExt+")")
+	Cmd.Flags().Int("min-papers", 100, "minimum number of papers with a year mentioning software for it to be included")
+	Cmd.Flags().String("ignore-file", "", "file listing software to ignore, one per line (default: a built-in list of generic words)")
+	Cmd.Flags().String("summary-out", "", "file to write each software's first, last, and peak years to, as .csv or .parquet")
+}
+
+var Cmd = cobra.Command{
+	Use:   "trends DIR OUTFILE",
+	Short: "Count the papers mentioning each piece of software by publication year",
+	Long: `Count the papers mentioning each piece of software by publication year.
+
+DIR is an output directory of extract software. The mentions table there is
+joined with the papers table written by extract papers to find the year of
+each paper mentioning software. Papers without a year are left out.
+
+OUTFILE records as .csv or .parquet, for each piece of software and each year
+from the first it was mentioned to the last year of any paper,
+
+  papers        papers that year mentioning it
+  yearPapers    papers that year
+  share         papers / yearPapers
+  papersGrowth  relative change in papers since the year before
+  shareGrowth   relative change in share since the year before
+
+Growth is blank where the year before had no mentions, or for shareGrowth
+where the year had no papers. --summary-out records each piece of software's
+first and last years with mentions, and the year the greatest share of papers
+mentioned it.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runE,
+}
+
+var ErrTrends = errors.New("counting software trends")
+
+var (
+	trendsSchema = arrow.NewSchema([]arrow.Field{
+		{Name: "softwareId", Type: arrow.BinaryTypes.String},
+		{Name: "year", Type: arrow.PrimitiveTypes.Uint16},
+		{Name: "papers", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "yearPapers", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "share", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "papersGrowth", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "shareGrowth", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+
+	summarySchema = arrow.NewSchema([]arrow.Field{
+		{Name: "softwareId", Type: arrow.BinaryTypes.String},
+		{Name: "papers", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "firstYear", Type: arrow.PrimitiveTypes.Uint16},
+		{Name: "lastYear", Type: arrow.PrimitiveTypes.Uint16},
+		{Name: "peakYear", Type: arrow.PrimitiveTypes.Uint16},
+		{Name: "peakPapers", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "peakShare", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+)
+
+func runE(cmd *cobra.Command, args []string) error {
+	inDir := args[0]
+	outPath := args[1]
+
+	err := tables.CheckFormat(outPath)
+	if err != nil {
+		return err
+	}
+
+	summaryPath, err := cmd.Flags().GetString("summary-out")
+	if err != nil {
+		return err
+	}
+	if summaryPath != "" {
+		err = tables.CheckFormat(summaryPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	papersPath, err := cmd.Flags().GetString("papers")
+	if err != nil {
+		return err
+	}
+	if papersPath == "" {
+		papersPath = filepath.Join(inDir, tables.Papers+tables.ParquetExt)
+	}
+
+	minPapers, err := cmd.Flags().GetInt("min-papers")
+	if err != nil {
+		return err
+	}
+
+	ignore := comentions.DefaultIgnore
+	ignorePath, err := cmd.Flags().GetString("ignore-file")
+	if err != nil {
+		return err
+	}
+	if ignorePath != "" {
+		ignore, err = comentions.ReadIgnore(ignorePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	years, err := readYears(cmd, papersPath)
+	if err != nil {
+		return err
+	}
+
+	mentionsPath := filepath.Join(inDir, tables.Mentions+tables.ParquetExt)
+	softwareByPaper, err := comentions.ReadMentions(cmd.Context(), mentionsPath, ignore)
+	if err != nil {
+		return err
+	}
+
+	counts := Count(years, softwareByPaper)
+	trends := counts.Trends(minPapers)
+
+	err = writeTrends(outPath, counts, trends)
+	if err != nil {
+		return err
+	}
+	slog.Info("wrote software trends", "path", outPath, "software", len(trends))
+
+	if summaryPath != "" {
+		err = writeSummary(summaryPath, trends)
+		if err != nil {
+			return err
+		}
+		slog.Info("wrote software trend summary", "path", summaryPath)
+	}
+
+	return nil
+}
+
+// readYears reads the publication year of each paper, keyed by UUID. Papers
+// without a year have year 0.
+func readYears(cmd *cobra.Command, papersPath string) (map[string]uint16, error) {
+	years := make(map[string]uint16)
+
+	for record, err := range tables.Records(cmd.Context(), papersPath) {
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTrends, err)
+		}
+
+		uuidColumn, err := tables.Column(record, "uuid")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrTrends, papersPath, err)
+		}
+		yearColumn, err := tables.Column(record, "year")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrTrends, papersPath, err)
+		}
+		yearValues, ok := yearColumn.(*array.Uint16)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q: got year of type %s but want %s",
+				ErrTrends, papersPath, yearColumn.DataType(), arrow.PrimitiveTypes.Uint16)
+		}
+
+		for row := range int(record.NumRows()) {
+			years[tables.StringValue(uuidColumn, row)] = yearValues.Value(row)
+		}
+	}
+
+	return years, nil
+}
+
+// Counts are the numbers of papers published each year, and the numbers of
+// those mentioning each piece of software.
+type Counts struct {
+	// YearPapers is the number of papers published each year.
+	YearPapers map[uint16]int
+	// Software is the number of papers each year mentioning each piece of
+	// software.
+	Software map[string]map[uint16]int
+
+	FirstYear uint16
+	LastYear  uint16
+}
+
+// Count counts papers by year. Papers without a year, or mentioning software
+// but missing from years, aren't counted.
+func Count(years map[string]uint16, softwareByPaper comentions.SoftwareByPaper) *Counts {
+	counts := &Counts{
+		YearPapers: make(map[uint16]int),
+		Software:   make(map[string]map[uint16]int),
+	}
+
+	for _, year := range years {
+		if year == 0 {
+			continue
+		}
+		counts.YearPapers[year]++
+
+		if counts.FirstYear == 0 || year < counts.FirstYear {
+			counts.FirstYear = year
+		}
+		counts.LastYear = max(counts.LastYear, year)
+	}
+
+	noYear := 0
+	for paperId, paperSoftware := range softwareByPaper {
+		year := years[paperId]
+		if year == 0 {
+			noYear++
+			continue
+		}
+
+		for softwareId := range paperSoftware {
+			softwareYears, ok := counts.Software[softwareId]
+			if !ok {
+				softwareYears = make(map[uint16]int)
+				counts.Software[softwareId] = softwareYears
+			}
+			softwareYears[year]++
+		}
+	}
+
+	if noYear > 0 {
+		slog.Warn("left out papers mentioning software without a year", "papers", noYear)
+	}
+
+	return counts
+}
+
+// Trend is how often a piece of software was mentioned over time.
+type Trend struct {
+	SoftwareId string
+	// Papers is the number of papers with a year mentioning the software.
+	Papers int
+
+	// FirstYear and LastYear are the first and last years with papers
+	// mentioning the software.
+	FirstYear uint16
+	LastYear  uint16
+
+	// PeakYear is the year with the greatest share of papers mentioning the
+	// software. Ties go to the earliest year.
+	PeakYear   uint16
+	PeakPapers int
+	PeakShare  float64
+}
+
+// Trends summarises each piece of software mentioned in at least minPapers
+// papers, most mentioned first.
+func (c *Counts) Trends(minPapers int) []*Trend {
+	var trends []*Trend
+	for softwareId, softwareYears := range c.Software {
+		trend := &Trend{SoftwareId: softwareId}
+		for year, papers := range softwareYears {
+			trend.Papers += papers
+
+			if trend.FirstYear == 0 || year < trend.FirstYear {
+				trend.FirstYear = year
+			}
+			trend.LastYear = max(trend.LastYear, year)
+
+			share := c.Share(year, papers)
+			if share > trend.PeakShare || (share == trend.PeakShare && year < trend.PeakYear) {
+				trend.PeakYear = year
+				trend.PeakPapers = papers
+				trend.PeakShare = share
+			}
+		}
+
+		if trend.Papers >= minPapers {
+			trends = append(trends, trend)
+		}
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Papers != trends[j].Papers {
+			return trends[i].Papers > trends[j].Papers
+		}
+		return trends[i].SoftwareId < trends[j].SoftwareId
+	})
+
+	return trends
+}
+
+// Share is the share of papers published in year that papers is.
+func (c *Counts) Share(year uint16, papers int) float64 {
+	return float64(papers) / float64(c.YearPapers[year])
+}
+
+func writeTrends(outPath string, counts *Counts, trends []*Trend) error {
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), trendsSchema)
+	defer builder.Release()
+
+	for _, trend := range trends {
+		softwareYears := counts.Software[trend.SoftwareId]
+
+		// Loop over ints, as a uint16 would wrap around if LastYear is 65535.
+		for y := int(trend.FirstYear); y <= int(counts.LastYear); y++ {
+			year := uint16(y)
+			papers := softwareYears[year]
+			yearPapers := counts.YearPapers[year]
+
+			builder.Field(0).(*array.StringBuilder).Append(trend.SoftwareId)
+			builder.Field(1).(*array.Uint16Builder).Append(year)
+			builder.Field(2).(*array.Int64Builder).Append(int64(papers))
+			builder.Field(3).(*array.Int64Builder).Append(int64(yearPapers))
+			if yearPapers > 0 {
+				builder.Field(4).(*array.Float64Builder).Append(counts.Share(year, papers))
+			} else {
+				builder.Field(4).(*array.Float64Builder).Append(0)
+			}
+
+			lastPapers := softwareYears[year-1]
+			if year > trend.FirstYear && lastPapers > 0 {
+				builder.Field(5).(*array.Float64Builder).Append(float64(papers-lastPapers) / float64(lastPapers))
+			} else {
+				builder.Field(5).(*array.Float64Builder).AppendNull()
+			}
+
+			if year > trend.FirstYear && lastPapers > 0 && yearPapers > 0 {
+				lastShare := counts.Share(year-1, lastPapers)
+				builder.Field(6).(*array.Float64Builder).Append((counts.Share(year, papers) - lastShare) / lastShare)
+			} else {
+				builder.Field(6).(*array.Float64Builder).AppendNull()
+			}
+		}
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	return tables.Write(outPath, record)
+}
+
+func writeSummary(outPath string, trends []*Trend) error {
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), summarySchema)
+	defer builder.Release()
+
+	for _, trend := range trends {
+		builder.Field(0).(*array.StringBuilder).Append(trend.SoftwareId)
+		builder.Field(1).(*array.Int64Builder).Append(int64(trend.Papers))
+		builder.Field(2).(*array.Uint16Builder).Append(trend.FirstYear)
+		builder.Field(3).(*array.Uint16Builder).Append(trend.LastYear)
+		builder.Field(4).(*array.Uint16Builder).Append(trend.PeakYear)
+		builder.Field(5).(*array.Int64Builder).Append(int64(trend.PeakPapers))
+		builder.Field(6).(*array.Float64Builder).Append(trend.PeakShare)
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	return tables.Write(outPath, record)
+}
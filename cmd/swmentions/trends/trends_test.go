@@ -0,0 +1,135 @@
+package trends
+
+import (
+	"encoding/csv"
+	"github.com/google/go-cmp/cmp"
+	"github.com/willbeason/software-mentions/pkg/comentions"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testCounts counts papers from 2000 to 2003, with none in 2002. Software "X"
+// has the same share of papers in 2000, 2001, and 2003, and none in 2002.
+// Papers "z", without a year, and "missing", not in the papers table, aren't
+// counted.
+func testCounts() *Counts {
+	years := map[string]uint16{
+		"a": 2000, "b": 2000, "c": 2000, "d": 2000,
+		"e": 2001, "f": 2001,
+		"h": 2003, "i": 2003,
+		"z": 0,
+	}
+
+	softwareByPaper := comentions.SoftwareByPaper{
+		"a":       {"X": true},
+		"b":       {"X": true, "Y": true},
+		"e":       {"X": true},
+		"h":       {"Y": true},
+		"i":       {"X": true},
+		"z":       {"X": true, "Y": true},
+		"missing": {"X": true, "Y": true},
+	}
+
+	return Count(years, softwareByPaper)
+}
+
+func TestCount(t *testing.T) {
+	counts := testCounts()
+
+	want := &Counts{
+		YearPapers: map[uint16]int{2000: 4, 2001: 2, 2003: 2},
+		Software: map[string]map[uint16]int{
+			"X": {2000: 2, 2001: 1, 2003: 1},
+			"Y": {2000: 1, 2003: 1},
+		},
+		FirstYear: 2000,
+		LastYear:  2003,
+	}
+	if diff := cmp.Diff(want, counts); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCounts_Trends(t *testing.T) {
+	tcs := []struct {
+		name      string
+		minPapers int
+		want      []*Trend
+	}{{
+		name:      "all",
+		minPapers: 1,
+		want: []*Trend{{
+			// 2000, 2001, and 2003 tie for the peak share, so it goes to
+			// 2000.
+			SoftwareId: "X", Papers: 4,
+			FirstYear: 2000, LastYear: 2003,
+			PeakYear: 2000, PeakPapers: 2, PeakShare: 0.5,
+		}, {
+			SoftwareId: "Y", Papers: 2,
+			FirstYear: 2000, LastYear: 2003,
+			PeakYear: 2003, PeakPapers: 1, PeakShare: 0.5,
+		}},
+	}, {
+		name:      "min papers",
+		minPapers: 3,
+		want: []*Trend{{
+			SoftwareId: "X", Papers: 4,
+			FirstYear: 2000, LastYear: 2003,
+			PeakYear: 2000, PeakPapers: 2, PeakShare: 0.5,
+		}},
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Years are visited in map order, so repeat to exercise the
+			// tie-break.
+			for range 20 {
+				got := testCounts().Trends(tc.minPapers)
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Fatal(diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTrends(t *testing.T) {
+	counts := testCounts()
+	outPath := filepath.Join(t.TempDir(), "trends.csv")
+
+	err := writeTrends(outPath, counts, counts.Trends(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	got, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Growth is blank after a year without mentions, and share growth is
+	// blank in 2002 as no papers were published.
+	want := [][]string{
+		{"softwareId", "year", "papers", "yearPapers", "share", "papersGrowth", "shareGrowth"},
+		{"X", "2000", "2", "4", "0.5", "", ""},
+		{"X", "2001", "1", "2", "0.5", "-0.5", "0"},
+		{"X", "2002", "0", "0", "0", "-1", ""},
+		{"X", "2003", "1", "2", "0.5", "", ""},
+		{"Y", "2000", "1", "4", "0.25", "", ""},
+		{"Y", "2001", "0", "2", "0", "-1", "-1"},
+		{"Y", "2002", "0", "0", "0", "", ""},
+		{"Y", "2003", "1", "2", "0.5", "", ""},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -6,6 +6,7 @@ require (
 	github.com/apache/arrow/go/v18 v18.0.0-20240927152746-6f64af54ea36
 	github.com/google/go-cmp v0.6.0
 	github.com/google/uuid v1.6.0
+	github.com/klauspost/compress v1.17.10
 	github.com/spf13/cobra v1.8.1
 	github.com/vbauerster/mpb v3.4.0+incompatible
 	github.com/willbeason/bondsmith v0.1.4
@@ -23,7 +24,6 @@ require (
 	github.com/google/flatbuffers v24.3.25+incompatible // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/klauspost/asmfmt v1.3.2 // indirect
-	github.com/klauspost/compress v1.17.10 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/minio/asm2plan9s v0.0.0-20200509001527-cdd76441f9d8 // indirect
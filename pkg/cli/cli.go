@@ -0,0 +1,217 @@
+// Package cli holds the flags and setup shared by every swmentions subcommand.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/willbeason/software-mentions/pkg/progress"
+	"github.com/willbeason/software-mentions/pkg/rejects"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+const (
+	FlagCpuProfile = "cpuprofile"
+	FlagQuiet      = "quiet"
+	FlagWorkers    = "workers"
+	FlagLogLevel   = "log-level"
+	FlagLogFormat  = "log-format"
+
+	FlagProgressInterval = "progress-interval"
+
+	FlagOnError       = "on-error"
+	FlagRejects       = "rejects"
+	FlagQuarantineDir = "quarantine-dir"
+)
+
+var ErrCli = errors.New("setting up command")
+
+// AddFlags registers the shared flags on root so every subcommand inherits
+// them.
+func AddFlags(root *cobra.Command) {
+	flags := root.PersistentFlags()
+	flags.String(FlagCpuProfile, "", "write cpu profile to `file`")
+	flags.Bool(FlagQuiet, false, "hide progress bars and informational log messages")
+	flags.Int(FlagWorkers, runtime.NumCPU(), "number of files or directories to process at once")
+	flags.String(FlagLogLevel, "info", "minimum level of log messages: debug, info, warn, or error")
+	flags.String(FlagLogFormat, "text", "format of log messages: text or json")
+	flags.Duration(FlagProgressInterval, progress.DefaultInterval, "how often to log progress when stdout is not a terminal")
+	flags.String(FlagOnError, string(rejects.Fail), "what to do with input files which can't be read: fail, skip, or quarantine")
+	flags.String(FlagRejects, "", "file to list skipped input files and their errors in, one JSON object per line")
+	flags.String(FlagQuarantineDir, "", "directory to copy skipped input files to with --on-error=quarantine")
+}
+
+// profile is the CPU profile being written, if any.
+var profile *os.File
+
+// Setup configures the default logger and starts CPU profiling if requested.
+// It is meant to be the root command's PersistentPreRunE.
+func Setup(cmd *cobra.Command, _ []string) error {
+	err := setupLogging(cmd)
+	if err != nil {
+		return err
+	}
+
+	profilePath, err := cmd.Flags().GetString(FlagCpuProfile)
+	if err != nil {
+		return err
+	}
+	if profilePath == "" {
+		return nil
+	}
+
+	profile, err = os.Create(profilePath)
+	if err != nil {
+		return fmt.Errorf("%w: creating CPU profile %q: %w", ErrCli, profilePath, err)
+	}
+
+	err = pprof.StartCPUProfile(profile)
+	if err != nil {
+		_ = profile.Close()
+		profile = nil
+		return fmt.Errorf("%w: starting CPU profile: %w", ErrCli, err)
+	}
+	slog.Debug("writing CPU profile", "path", profilePath)
+
+	return nil
+}
+
+// Teardown stops CPU profiling, if it was started. Unlike PersistentPostRunE
+// it should be called even if the command fails, so profiles of failed runs
+// are still written.
+func Teardown() {
+	if profile == nil {
+		return
+	}
+
+	pprof.StopCPUProfile()
+	err := profile.Close()
+	if err != nil {
+		slog.Error("closing CPU profile", "err", err)
+	}
+	profile = nil
+}
+
+func setupLogging(cmd *cobra.Command) error {
+	levelString, err := cmd.Flags().GetString(FlagLogLevel)
+	if err != nil {
+		return err
+	}
+
+	var level slog.Level
+	err = level.UnmarshalText([]byte(levelString))
+	if err != nil {
+		return fmt.Errorf("%w: --%s must be debug, info, warn, or error, not %q", ErrCli, FlagLogLevel, levelString)
+	}
+	if Quiet(cmd) {
+		level = max(level, slog.LevelWarn)
+	}
+
+	format, err := cmd.Flags().GetString(FlagLogFormat)
+	if err != nil {
+		return err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, options)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, options)))
+	default:
+		return fmt.Errorf("%w: --%s must be text or json, not %q", ErrCli, FlagLogFormat, format)
+	}
+
+	return nil
+}
+
+// Quiet is whether progress bars and informational messages are hidden.
+func Quiet(cmd *cobra.Command) bool {
+	quiet, err := cmd.Flags().GetBool(FlagQuiet)
+	// Commands run outside of the swmentions root don't have the flag.
+	return err == nil && quiet
+}
+
+// Workers is the number of files or directories to process at once.
+func Workers(cmd *cobra.Command) (int, error) {
+	workers, err := cmd.Flags().GetInt(FlagWorkers)
+	if err != nil {
+		return 0, err
+	}
+	if workers < 1 {
+		return 0, fmt.Errorf("%w: --%s must be at least 1, not %d", ErrCli, FlagWorkers, workers)
+	}
+
+	return workers, nil
+}
+
+// NewProgress returns progress bars drawn on the terminal, or logged every
+// --progress-interval if stdout is not a terminal. Nothing is shown if --quiet
+// is set.
+func NewProgress(cmd *cobra.Command) (*progress.Progress, error) {
+	interval, err := cmd.Flags().GetDuration(FlagProgressInterval)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("%w: --%s must be positive, not %v", ErrCli, FlagProgressInterval, interval)
+	}
+
+	return progress.New(Quiet(cmd), interval), nil
+}
+
+// NewRejects returns the handler chosen by --on-error for input files under
+// root which can't be read. The returned function closes the rejects report
+// and should be deferred.
+func NewRejects(cmd *cobra.Command, root string) (*rejects.Handler, func(), error) {
+	policy, err := cmd.Flags().GetString(FlagOnError)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reportPath, err := cmd.Flags().GetString(FlagRejects)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quarantineDir, err := cmd.Flags().GetString(FlagQuarantineDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler := &rejects.Handler{
+		Policy:        rejects.Policy(policy),
+		QuarantineDir: quarantineDir,
+		Root:          root,
+	}
+
+	switch handler.Policy {
+	case rejects.Fail, rejects.Skip:
+	case rejects.Quarantine:
+		if quarantineDir == "" {
+			return nil, nil, fmt.Errorf("%w: --%s=%s requires --%s", ErrCli, FlagOnError, rejects.Quarantine, FlagQuarantineDir)
+		}
+	default:
+		return nil, nil, fmt.Errorf("%w: --%s must be %s, %s, or %s, not %q", ErrCli, FlagOnError, rejects.Fail, rejects.Skip, rejects.Quarantine, policy)
+	}
+
+	if reportPath == "" {
+		return handler, func() {}, nil
+	}
+
+	report, err := os.Create(reportPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: creating rejects report %q: %w", ErrCli, reportPath, err)
+	}
+	handler.Report = report
+
+	return handler, func() {
+		err := report.Close()
+		if err != nil {
+			slog.Error("closing rejects report", "err", err)
+		}
+	}, nil
+}
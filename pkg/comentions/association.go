@@ -0,0 +1,230 @@
+package comentions
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Measure is a way of scoring how strongly a pair of software is associated.
+type Measure string
+
+const (
+	// MeasurePapers is the raw number of papers mentioning both.
+	MeasurePapers Measure = "papers"
+	// MeasurePMI is pointwise mutual information, in bits.
+	MeasurePMI Measure = "pmi"
+	// MeasureNPMI is PMI normalized to lie between -1 and 1.
+	MeasureNPMI Measure = "npmi"
+	// MeasureJaccard is the share of papers mentioning either which mention
+	// both.
+	MeasureJaccard Measure = "jaccard"
+	// MeasureLift is how many times more often the pair is mentioned together
+	// than expected if mentions were independent.
+	MeasureLift Measure = "lift"
+	// MeasureG2 is the log-likelihood ratio statistic of the 2x2 contingency
+	// table of papers mentioning each.
+	MeasureG2 Measure = "g2"
+	// MeasureP is the probability of at least as many papers mentioning both
+	// under a hypergeometric null.
+	MeasureP Measure = "p"
+	// MeasureQ is MeasureP adjusted for the number of pairs tested.
+	MeasureQ Measure = "q"
+)
+
+// Measures lists every Measure.
+var Measures = []Measure{
+	MeasurePapers, MeasurePMI, MeasureNPMI, MeasureJaccard, MeasureLift, MeasureG2, MeasureP, MeasureQ,
+}
+
+// ParseMeasure returns the Measure named s.
+func ParseMeasure(s string) (Measure, error) {
+	for _, m := range Measures {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown measure %q, want one of %v", ErrComentions, s, Measures)
+}
+
+// Ascending is whether smaller values of the measure mean stronger
+// association.
+func (m Measure) Ascending() bool {
+	return m == MeasureP || m == MeasureQ
+}
+
+// Association is how strongly a pair of software is associated, compared to
+// what would be expected if papers mentioned each independently.
+type Association struct {
+	// Papers is the number of papers mentioning both.
+	Papers int
+
+	PMI     float64
+	NPMI    float64
+	Jaccard float64
+	Lift    float64
+	G2      float64
+
+	// PValue is the one-sided probability of at least Papers papers
+	// mentioning both, if the papers mentioning the second were drawn at
+	// random from all papers.
+	PValue float64
+	// QValue is PValue with the Benjamini-Hochberg adjustment for the number
+	// of pairs scored together.
+	QValue float64
+}
+
+// Value is the association's score under m.
+func (a *Association) Value(m Measure) float64 {
+	switch m {
+	case MeasurePMI:
+		return a.PMI
+	case MeasureNPMI:
+		return a.NPMI
+	case MeasureJaccard:
+		return a.Jaccard
+	case MeasureLift:
+		return a.Lift
+	case MeasureG2:
+		return a.G2
+	case MeasureP:
+		return a.PValue
+	case MeasureQ:
+		return a.QValue
+	default:
+		return float64(a.Papers)
+	}
+}
+
+// Associate scores each pair of software. Probabilities are relative to the
+// papers mentioning any software, since papers mentioning none aren't in the
+// mentions table. The QValues are adjusted for the number of dyads.
+func (c *Counts) Associate(dyads []MentionDyad) map[MentionDyad]*Association {
+	associations := make(map[MentionDyad]*Association, len(dyads))
+	for _, dyad := range dyads {
+		associations[dyad] = associate(c.Papers, c.Software[dyad.A], c.Software[dyad.B], c.Dyads[dyad])
+	}
+
+	adjustPValues(dyads, associations)
+
+	return associations
+}
+
+// associate scores a pair of software mentioned by nA and nB of n papers, and
+// together by nAB.
+func associate(n, nA, nB, nAB int) *Association {
+	a := &Association{Papers: nAB}
+
+	N, A, B, AB := float64(n), float64(nA), float64(nB), float64(nAB)
+
+	a.Lift = AB * N / (A * B)
+	a.PMI = math.Log2(a.Lift)
+	if nAB == n {
+		// Every paper mentions both, so they are perfectly associated.
+		a.NPMI = 1
+	} else {
+		a.NPMI = a.PMI / -math.Log2(AB/N)
+	}
+	a.Jaccard = AB / (A + B - AB)
+
+	// The observed and expected counts of the 2x2 contingency table of papers
+	// mentioning A or not and B or not.
+	observed := [4]float64{AB, A - AB, B - AB, N - A - B + AB}
+	expected := [4]float64{A * B / N, A * (N - B) / N, (N - A) * B / N, (N - A) * (N - B) / N}
+	for i, o := range observed {
+		if o > 0 {
+			a.G2 += 2 * o * math.Log(o/expected[i])
+		}
+	}
+
+	a.PValue = hypergeometricUpperTail(n, nA, nB, nAB)
+
+	return a
+}
+
+// hypergeometricUpperTail is the probability of drawing at least k marked
+// items when drawing d items without replacement from n items of which m are
+// marked.
+func hypergeometricUpperTail(n, m, d, k int) float64 {
+	lo := max(0, d-(n-m))
+	hi := min(m, d)
+	if k <= lo {
+		return 1
+	} else if k > hi {
+		return 0
+	}
+
+	logC := lchoose(n, d)
+	logP := func(i int) float64 {
+		return lchoose(m, i) + lchoose(n-m, d-i) - logC
+	}
+
+	// Terms shrink moving away from the mode, so sum whichever tail doesn't
+	// contain it and stop once terms no longer matter.
+	mode := int(float64(d+1) * float64(m+1) / float64(n+2))
+	if k > mode {
+		return min(1, sumTerms(logP, k, hi, 1))
+	}
+	return max(0, 1-sumTerms(logP, k-1, lo, -1))
+}
+
+// sumTerms sums exp(logP(i)) for i from start to end inclusive, stepping by
+// step, stopping early once terms are too small to change the sum.
+func sumTerms(logP func(int) float64, start, end, step int) float64 {
+	sum := 0.0
+	for i := start; i*step <= end*step; i += step {
+		term := math.Exp(logP(i))
+		sum += term
+		if term < sum*1e-17 {
+			break
+		}
+	}
+	return sum
+}
+
+func lchoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// adjustPValues sets the QValue of each association with the Benjamini-Hochberg
+// procedure.
+func adjustPValues(dyads []MentionDyad, associations map[MentionDyad]*Association) {
+	byP := make([]*Association, len(dyads))
+	for i, dyad := range dyads {
+		byP[i] = associations[dyad]
+	}
+	sort.Slice(byP, func(i, j int) bool {
+		return byP[i].PValue < byP[j].PValue
+	})
+
+	q := 1.0
+	for i := len(byP) - 1; i >= 0; i-- {
+		q = min(q, byP[i].PValue*float64(len(byP))/float64(i+1))
+		byP[i].QValue = q
+	}
+}
+
+// SortDyads orders dyads from most to least associated under m. Ties are
+// broken by the number of papers mentioning both, and then by name.
+func SortDyads(dyads []MentionDyad, associations map[MentionDyad]*Association, m Measure) {
+	sort.SliceStable(dyads, func(i, j int) bool {
+		ai, aj := associations[dyads[i]], associations[dyads[j]]
+		vi, vj := ai.Value(m), aj.Value(m)
+		if vi != vj {
+			if m.Ascending() {
+				return vi < vj
+			}
+			return vi > vj
+		}
+		if ai.Papers != aj.Papers {
+			return ai.Papers > aj.Papers
+		}
+		if dyads[i].A != dyads[j].A {
+			return dyads[i].A < dyads[j].A
+		}
+		return dyads[i].B < dyads[j].B
+	})
+}
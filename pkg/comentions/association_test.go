@@ -0,0 +1,162 @@
+package comentions
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"math"
+	"testing"
+)
+
+// near reports whether got is within a relative tolerance of want.
+func near(got, want, tolerance float64) bool {
+	if want == 0 {
+		return got == 0
+	}
+	return math.Abs(got-want) <= tolerance*math.Abs(want)
+}
+
+func TestHypergeometricUpperTail(t *testing.T) {
+	// Expected values are exact sums of the probability mass function, as
+	// given by scipy.stats.hypergeom.sf(k-1, n, m, d).
+	tcs := []struct {
+		n, m, d, k int
+		want       float64
+	}{
+		{n: 100, m: 20, d: 30, k: 10, want: 0.0306964293543367},
+		{n: 100, m: 20, d: 30, k: 3, want: 0.9773188336693431},
+		{n: 100, m: 20, d: 30, k: 0, want: 1},
+		{n: 100, m: 20, d: 30, k: 20, want: 5.605587161662142e-14},
+		{n: 100, m: 20, d: 30, k: 21, want: 0},
+		{n: 5, m: 3, d: 2, k: 2, want: 0.3},
+		{n: 5, m: 3, d: 3, k: 1, want: 1},
+		{n: 1_000_000, m: 50_000, d: 30_000, k: 2_000, want: 5.1314870324e-38},
+		{n: 1_000_000, m: 50_000, d: 30_000, k: 1_400, want: 0.99681262},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%d,%d,%d,%d", tc.n, tc.m, tc.d, tc.k), func(t *testing.T) {
+			got := hypergeometricUpperTail(tc.n, tc.m, tc.d, tc.k)
+			if !near(got, tc.want, 1e-8) {
+				t.Errorf("got %g, want %g", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAssociate(t *testing.T) {
+	tcs := []struct {
+		name           string
+		n, nA, nB, nAB int
+		want           *Association
+	}{{
+		name: "positive association",
+		n:    5, nA: 3, nB: 2, nAB: 2,
+		want: &Association{
+			Papers:  2,
+			PMI:     0.7369655941662062,
+			NPMI:    0.5574929506502402,
+			Jaccard: 2.0 / 3.0,
+			Lift:    5.0 / 3.0,
+			G2:      2.9110316603236877,
+			PValue:  0.3,
+		},
+	}, {
+		name: "larger table",
+		n:    100, nA: 20, nB: 30, nAB: 10,
+		want: &Association{
+			Papers:  10,
+			PMI:     math.Log2(10.0 / 6.0),
+			NPMI:    math.Log2(10.0/6.0) / math.Log2(10),
+			Jaccard: 0.25,
+			Lift:    10.0 / 6.0,
+			G2:      4.473350049571542,
+			PValue:  0.0306964293543367,
+		},
+	}, {
+		name: "independent",
+		n:    100, nA: 20, nB: 50, nAB: 10,
+		want: &Association{
+			Papers:  10,
+			PMI:     0,
+			NPMI:    0,
+			Jaccard: 10.0 / 60.0,
+			Lift:    1,
+			G2:      0,
+			PValue:  0.5984356088532747,
+		},
+	}, {
+		name: "every paper mentions both",
+		n:    4, nA: 4, nB: 4, nAB: 4,
+		want: &Association{
+			Papers:  4,
+			PMI:     0,
+			NPMI:    1,
+			Jaccard: 1,
+			Lift:    1,
+			G2:      0,
+			PValue:  1,
+		},
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got := associate(tc.n, tc.nA, tc.nB, tc.nAB)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateApprox(1e-9, 1e-12)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestAdjustPValues(t *testing.T) {
+	// Expected values are as given by
+	// statsmodels.stats.multitest.multipletests(p, method="fdr_bh").
+	p := []float64{0.01, 0.04, 0.03, 0.005, 0.5}
+	want := []float64{0.025, 0.05, 0.05, 0.025, 0.5}
+
+	dyads := make([]MentionDyad, len(p))
+	associations := make(map[MentionDyad]*Association)
+	for i := range p {
+		dyads[i] = MentionDyad{A: "a", B: fmt.Sprint(i)}
+		associations[dyads[i]] = &Association{PValue: p[i]}
+	}
+
+	adjustPValues(dyads, associations)
+
+	got := make([]float64, len(p))
+	for i, dyad := range dyads {
+		got[i] = associations[dyad].QValue
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(1e-12, 0)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCounts_Associate(t *testing.T) {
+	counts := Count(SoftwareByPaper{
+		"p1": {"R": true, "limma": true, "Python": true},
+		"p2": {"R": true, "limma": true},
+		"p3": {"R": true, "Python": true},
+		"p4": {"Python": true},
+		"p5": {"ImageJ": true},
+	}, 1)
+
+	dyads := counts.RankDyads(1)
+	associations := counts.Associate(dyads)
+
+	got := make(map[MentionDyad][2]float64)
+	for dyad, a := range associations {
+		got[dyad] = [2]float64{a.PValue, a.QValue}
+	}
+
+	// The p-values 0.3, 0.7, and 0.9 are each adjusted to 0.9.
+	want := map[MentionDyad][2]float64{
+		{A: "R", B: "limma"}:      {0.3, 0.9},
+		{A: "Python", B: "R"}:     {0.7, 0.9},
+		{A: "Python", B: "limma"}: {0.9, 0.9},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApprox(1e-12, 0)); diff != "" {
+		t.Error(diff)
+	}
+}
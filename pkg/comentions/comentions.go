@@ -0,0 +1,232 @@
+// Package comentions counts the papers mentioning each piece of software, and
+// each pair of software mentioned in the same paper.
+package comentions
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/willbeason/software-mentions/pkg/tables"
+	"os"
+	"sort"
+	"strings"
+)
+
+var ErrComentions = errors.New("counting software co-mentions")
+
+// DefaultIgnore is software whose normalized names are generic words rather
+// than the names of tools.
+var DefaultIgnore = map[string]bool{
+	"script":    true,
+	"code":      true,
+	"scripts":   true,
+	"survival":  true,
+	"library":   true,
+	"software":  true,
+	"interface": true,
+	"program":   true,
+}
+
+// ReadIgnore reads a list of software to ignore, one per line. Blank lines and
+// lines starting with # are skipped.
+func ReadIgnore(path string) (map[string]bool, error) {
+	ignoreFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrComentions, err)
+	}
+	defer func() {
+		_ = ignoreFile.Close()
+	}()
+
+	ignore := make(map[string]bool)
+	scanner := bufio.NewScanner(ignoreFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ignore[line] = true
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading %q: %w", ErrComentions, path, err)
+	}
+
+	return ignore, nil
+}
+
+// SoftwareByPaper is the set of software each paper mentions, keyed by paper
+// ID.
+type SoftwareByPaper map[string]map[string]bool
+
+// ReadMentions reads the software each paper mentions from a mentions table,
+// leaving out ignored software.
+func ReadMentions(ctx context.Context, mentionsPath string, ignore map[string]bool) (SoftwareByPaper, error) {
+	softwareByPaper := make(SoftwareByPaper)
+
+	for record, err := range tables.Records(ctx, mentionsPath) {
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrComentions, err)
+		}
+
+		paperIds := record.Column(0).(*array.String)
+		softwareIds := record.Column(1).(*array.String)
+
+		for row := range int(record.NumRows()) {
+			paperId := paperIds.Value(row)
+			softwareId := softwareIds.Value(row)
+
+			if ignore[softwareId] {
+				continue
+			}
+
+			paperSoftware, ok := softwareByPaper[paperId]
+			if !ok {
+				paperSoftware = make(map[string]bool)
+				softwareByPaper[paperId] = paperSoftware
+			}
+
+			paperSoftware[softwareId] = true
+		}
+	}
+
+	return softwareByPaper, nil
+}
+
+// Software is what the software table records about a piece of software.
+type Software struct {
+	WikidataId   string
+	SoftwareType string
+}
+
+// ReadSoftware reads the software table, keyed by normalized name.
+func ReadSoftware(ctx context.Context, softwarePath string) (map[string]*Software, error) {
+	software := make(map[string]*Software)
+
+	for record, err := range tables.Records(ctx, softwarePath) {
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrComentions, err)
+		}
+
+		var columns [3]arrow.Array
+		for i, name := range []string{"normalizedForm", "wikidataId", "softwareType"} {
+			columns[i], err = tables.Column(record, name)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q: %w", ErrComentions, softwarePath, err)
+			}
+		}
+
+		for row := range int(record.NumRows()) {
+			software[tables.StringValue(columns[0], row)] = &Software{
+				WikidataId:   tables.StringValue(columns[1], row),
+				SoftwareType: tables.StringValue(columns[2], row),
+			}
+		}
+	}
+
+	return software, nil
+}
+
+// MentionDyad is a pair of software mentioned in the same paper. The first is
+// always less than the second, so each pair has one MentionDyad.
+type MentionDyad struct {
+	A string
+	B string
+}
+
+// Counts are the numbers of papers mentioning software and pairs of software.
+type Counts struct {
+	// Papers is the number of papers mentioning any software.
+	Papers int
+
+	// Software is the number of papers mentioning each piece of software.
+	Software map[string]int
+
+	// Dyads is the number of papers mentioning each pair of software. Only
+	// pairs of software mentioned in at least the minimum number of papers
+	// passed to Count are included.
+	Dyads map[MentionDyad]int
+}
+
+// Count counts the papers mentioning each piece of software, and the papers
+// mentioning each pair of software which are each mentioned in at least
+// minPapers papers.
+func Count(softwareByPaper SoftwareByPaper, minPapers int) *Counts {
+	counts := &Counts{
+		Papers:   len(softwareByPaper),
+		Software: make(map[string]int),
+		Dyads:    make(map[MentionDyad]int),
+	}
+
+	for _, paperSoftware := range softwareByPaper {
+		for softwareId := range paperSoftware {
+			counts.Software[softwareId]++
+		}
+	}
+
+	for _, paperSoftware := range softwareByPaper {
+		for k := range paperSoftware {
+			if counts.Software[k] < minPapers {
+				continue
+			}
+			for l := range paperSoftware {
+				if k >= l || counts.Software[l] < minPapers {
+					continue
+				}
+
+				counts.Dyads[MentionDyad{A: k, B: l}]++
+			}
+		}
+	}
+
+	return counts
+}
+
+// RankSoftware lists the software mentioned in at least minPapers papers, most
+// mentioned first. Ties are broken by name so rankings are reproducible.
+func (c *Counts) RankSoftware(minPapers int) []string {
+	var softwareList []string
+	for softwareId, count := range c.Software {
+		if count >= minPapers {
+			softwareList = append(softwareList, softwareId)
+		}
+	}
+
+	sort.Slice(softwareList, func(i, j int) bool {
+		ci, cj := c.Software[softwareList[i]], c.Software[softwareList[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return softwareList[i] < softwareList[j]
+	})
+
+	return softwareList
+}
+
+// RankDyads lists the pairs of software mentioned together in at least minPair
+// papers, most mentioned first. Ties are broken by name.
+func (c *Counts) RankDyads(minPair int) []MentionDyad {
+	var dyadList []MentionDyad
+	for dyad, count := range c.Dyads {
+		if count >= minPair {
+			dyadList = append(dyadList, dyad)
+		}
+	}
+
+	sort.Slice(dyadList, func(i, j int) bool {
+		ci, cj := c.Dyads[dyadList[i]], c.Dyads[dyadList[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		if dyadList[i].A != dyadList[j].A {
+			return dyadList[i].A < dyadList[j].A
+		}
+		return dyadList[i].B < dyadList[j].B
+	})
+
+	return dyadList
+}
@@ -0,0 +1,392 @@
+package comentions
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/arrow/memory"
+	"github.com/willbeason/software-mentions/pkg/tables"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	GraphMLExt = ".graphml"
+	GEXFExt    = ".gexf"
+	PajekExt   = ".net"
+)
+
+// Network is the co-mention graph: software are nodes, and pairs of software
+// mentioned in the same papers are edges weighted by the number of papers.
+type Network struct {
+	Nodes []Node
+	Edges []Edge
+
+	// Measure is the association measure recorded on each edge.
+	Measure Measure
+}
+
+type Node struct {
+	Id string
+	// Papers is the number of papers mentioning the software.
+	Papers       int
+	WikidataId   string
+	SoftwareType string
+}
+
+type Edge struct {
+	Source string
+	Target string
+	// Weight is the number of papers mentioning both.
+	Weight int
+	// Measure is how strongly the two are associated under Network.Measure.
+	Measure float64
+}
+
+// NewNetwork builds the network of the software in softwareList and the
+// dyads between them. Node attributes are taken from software, which may be
+// nil.
+func NewNetwork(counts *Counts, softwareList []string, dyads []MentionDyad, associations map[MentionDyad]*Association, software map[string]*Software, measure Measure) *Network {
+	network := &Network{
+		Nodes:   make([]Node, len(softwareList)),
+		Edges:   make([]Edge, len(dyads)),
+		Measure: measure,
+	}
+
+	for i, softwareId := range softwareList {
+		network.Nodes[i] = Node{Id: softwareId, Papers: counts.Software[softwareId]}
+		if s := software[softwareId]; s != nil {
+			network.Nodes[i].WikidataId = s.WikidataId
+			network.Nodes[i].SoftwareType = s.SoftwareType
+		}
+	}
+
+	for i, dyad := range dyads {
+		association := associations[dyad]
+		network.Edges[i] = Edge{
+			Source:  dyad.A,
+			Target:  dyad.B,
+			Weight:  association.Papers,
+			Measure: association.Value(measure),
+		}
+	}
+
+	return network
+}
+
+// CheckNetworkFormat returns an error unless WriteNetwork knows the extension
+// of path.
+func CheckNetworkFormat(path string) error {
+	switch filepath.Ext(path) {
+	case GraphMLExt, GEXFExt, PajekExt:
+		return nil
+	}
+
+	if tables.CheckFormat(path) != nil {
+		return fmt.Errorf("%w: %q must end in %s, %s, %s, %s, or %s",
+			ErrComentions, path, GraphMLExt, GEXFExt, PajekExt, tables.ParquetExt, tables.CSVExt)
+	}
+
+	return nil
+}
+
+// WriteNetwork writes n to path in the format given by its extension: GraphML,
+// GEXF, Pajek, or an edge list table as Parquet or CSV. Edge lists and Pajek
+// files don't record node attributes.
+func WriteNetwork(path string, n *Network) error {
+	err := CheckNetworkFormat(path)
+	if err != nil {
+		return err
+	}
+
+	var write func(io.Writer, *Network) error
+	switch filepath.Ext(path) {
+	case GraphMLExt:
+		write = writeGraphML
+	case GEXFExt:
+		write = writeGEXF
+	case PajekExt:
+		write = writePajek
+	default:
+		return writeEdgeList(path, n)
+	}
+
+	outFile, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrComentions, err)
+	}
+	defer func() {
+		err := outFile.Close()
+		if err != nil {
+			slog.Error("closing output file", "err", err)
+		}
+	}()
+
+	writer := bufio.NewWriter(outFile)
+	err = write(writer, n)
+	if err != nil {
+		return fmt.Errorf("%w: writing %q: %w", ErrComentions, path, err)
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("%w: writing %q: %w", ErrComentions, path, err)
+	}
+
+	return nil
+}
+
+var edgeListSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "source", Type: arrow.BinaryTypes.String},
+	{Name: "target", Type: arrow.BinaryTypes.String},
+	{Name: "weight", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "measure", Type: arrow.PrimitiveTypes.Float64},
+}, nil)
+
+func writeEdgeList(path string, n *Network) error {
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), edgeListSchema)
+	defer builder.Release()
+
+	for _, edge := range n.Edges {
+		builder.Field(0).(*array.StringBuilder).Append(edge.Source)
+		builder.Field(1).(*array.StringBuilder).Append(edge.Target)
+		builder.Field(2).(*array.Int64Builder).Append(int64(edge.Weight))
+		builder.Field(3).(*array.Float64Builder).Append(edge.Measure)
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	return tables.Write(path, record)
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	Id   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	Id          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, n *Network) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{Id: "papers", For: "node", Name: "papers", Type: "int"},
+			{Id: "wikidataId", For: "node", Name: "wikidataId", Type: "string"},
+			{Id: "softwareType", For: "node", Name: "softwareType", Type: "string"},
+			{Id: "weight", For: "edge", Name: "weight", Type: "double"},
+			{Id: "measure", For: "edge", Name: string(n.Measure), Type: "double"},
+		},
+		Graph: graphMLGraph{
+			Id:          "comentions",
+			EdgeDefault: "undirected",
+			Nodes:       make([]graphMLNode, len(n.Nodes)),
+			Edges:       make([]graphMLEdge, len(n.Edges)),
+		},
+	}
+
+	for i, node := range n.Nodes {
+		doc.Graph.Nodes[i] = graphMLNode{
+			Id: node.Id,
+			Data: []graphMLData{
+				{Key: "papers", Value: strconv.Itoa(node.Papers)},
+				{Key: "wikidataId", Value: node.WikidataId},
+				{Key: "softwareType", Value: node.SoftwareType},
+			},
+		}
+	}
+
+	for i, edge := range n.Edges {
+		doc.Graph.Edges[i] = graphMLEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data: []graphMLData{
+				{Key: "weight", Value: strconv.Itoa(edge.Weight)},
+				{Key: "measure", Value: formatFloat(edge.Measure)},
+			},
+		}
+	}
+
+	return writeXML(w, doc)
+}
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	Id    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	Id        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	Id        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Weight    string         `xml:"weight,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func writeGEXF(w io.Writer, n *Network) error {
+	doc := gexf{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "undirected",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: []gexfAttribute{
+					{Id: "papers", Title: "papers", Type: "integer"},
+					{Id: "wikidataId", Title: "wikidataId", Type: "string"},
+					{Id: "softwareType", Title: "softwareType", Type: "string"},
+				}},
+				{Class: "edge", Attributes: []gexfAttribute{
+					{Id: "measure", Title: string(n.Measure), Type: "double"},
+				}},
+			},
+			Nodes: make([]gexfNode, len(n.Nodes)),
+			Edges: make([]gexfEdge, len(n.Edges)),
+		},
+	}
+
+	for i, node := range n.Nodes {
+		doc.Graph.Nodes[i] = gexfNode{
+			Id:    node.Id,
+			Label: node.Id,
+			AttValues: []gexfAttValue{
+				{For: "papers", Value: strconv.Itoa(node.Papers)},
+				{For: "wikidataId", Value: node.WikidataId},
+				{For: "softwareType", Value: node.SoftwareType},
+			},
+		}
+	}
+
+	for i, edge := range n.Edges {
+		doc.Graph.Edges[i] = gexfEdge{
+			Id:     strconv.Itoa(i),
+			Source: edge.Source,
+			Target: edge.Target,
+			Weight: strconv.Itoa(edge.Weight),
+			AttValues: []gexfAttValue{
+				{For: "measure", Value: formatFloat(edge.Measure)},
+			},
+		}
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc any) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// writePajek writes the network as a Pajek .net file, with edges weighted by
+// the number of papers.
+func writePajek(w io.Writer, n *Network) error {
+	index := make(map[string]int, len(n.Nodes))
+
+	_, err := fmt.Fprintf(w, "*Vertices %d\n", len(n.Nodes))
+	if err != nil {
+		return err
+	}
+	for i, node := range n.Nodes {
+		index[node.Id] = i + 1
+		// Pajek labels can't contain double quotes.
+		_, err = fmt.Fprintf(w, "%d \"%s\"\n", i+1, strings.ReplaceAll(node.Id, `"`, `'`))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(w, "*Edges")
+	if err != nil {
+		return err
+	}
+	for _, edge := range n.Edges {
+		_, err = fmt.Fprintf(w, "%d %d %d\n", index[edge.Source], index[edge.Target], edge.Weight)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,129 @@
+package comentions
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+// testNetwork is the network of Python, R, and limma, weighted by the number
+// of papers mentioning each pair and measured by Jaccard index.
+func testNetwork() *Network {
+	counts := Count(SoftwareByPaper{
+		"p1": {"R": true, "limma": true, "Python": true},
+		"p2": {"R": true, "limma": true},
+		"p3": {"R": true, "Python": true},
+		"p4": {"Python": true},
+		"p5": {"ImageJ": true},
+	}, 2)
+
+	dyads := counts.RankDyads(1)
+	software := map[string]*Software{
+		"R": {WikidataId: "Q206904", SoftwareType: "environment"},
+	}
+
+	return NewNetwork(counts, counts.RankSoftware(2), dyads, counts.Associate(dyads), software, MeasureJaccard)
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeGraphML(&buf, testNetwork())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := graphML{}
+	err = xml.Unmarshal(buf.Bytes(), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeData := func(papers, wikidataId, softwareType string) []graphMLData {
+		return []graphMLData{{"papers", papers}, {"wikidataId", wikidataId}, {"softwareType", softwareType}}
+	}
+	edgeData := func(weight, measure string) []graphMLData {
+		return []graphMLData{{"weight", weight}, {"measure", measure}}
+	}
+	want := graphMLGraph{
+		Id:          "comentions",
+		EdgeDefault: "undirected",
+		Nodes: []graphMLNode{
+			{Id: "Python", Data: nodeData("3", "", "")},
+			{Id: "R", Data: nodeData("3", "Q206904", "environment")},
+			{Id: "limma", Data: nodeData("2", "", "")},
+		},
+		Edges: []graphMLEdge{
+			{Source: "Python", Target: "R", Data: edgeData("2", "0.5")},
+			{Source: "R", Target: "limma", Data: edgeData("2", "0.6666666666666666")},
+			{Source: "Python", Target: "limma", Data: edgeData("1", "0.25")},
+		},
+	}
+	if diff := cmp.Diff(want, got.Graph); diff != "" {
+		t.Error(diff)
+	}
+
+	// The measure key is named for the measure.
+	for _, key := range got.Keys {
+		if key.Id == "measure" && key.Name != string(MeasureJaccard) {
+			t.Errorf("got measure named %q, want %q", key.Name, MeasureJaccard)
+		}
+	}
+}
+
+func TestWriteGEXF(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeGEXF(&buf, testNetwork())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := gexf{}
+	err = xml.Unmarshal(buf.Bytes(), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attValues := func(papers, wikidataId, softwareType string) []gexfAttValue {
+		return []gexfAttValue{{"papers", papers}, {"wikidataId", wikidataId}, {"softwareType", softwareType}}
+	}
+	wantNodes := []gexfNode{
+		{Id: "Python", Label: "Python", AttValues: attValues("3", "", "")},
+		{Id: "R", Label: "R", AttValues: attValues("3", "Q206904", "environment")},
+		{Id: "limma", Label: "limma", AttValues: attValues("2", "", "")},
+	}
+	if diff := cmp.Diff(wantNodes, got.Graph.Nodes); diff != "" {
+		t.Error(diff)
+	}
+
+	wantEdges := []gexfEdge{
+		{Id: "0", Source: "Python", Target: "R", Weight: "2", AttValues: []gexfAttValue{{"measure", "0.5"}}},
+		{Id: "1", Source: "R", Target: "limma", Weight: "2", AttValues: []gexfAttValue{{"measure", "0.6666666666666666"}}},
+		{Id: "2", Source: "Python", Target: "limma", Weight: "1", AttValues: []gexfAttValue{{"measure", "0.25"}}},
+	}
+	if diff := cmp.Diff(wantEdges, got.Graph.Edges); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestWritePajek(t *testing.T) {
+	var buf bytes.Buffer
+	err := writePajek(&buf, testNetwork())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Vertices are numbered from 1 in the order listed.
+	want := `*Vertices 3
+1 "Python"
+2 "R"
+3 "limma"
+*Edges
+1 2 2
+2 3 2
+1 3 1
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"container/heap"
+	"math/rand"
+)
+
+// Betweenness is the betweenness centrality of each node: the number of
+// shortest paths between other pairs of nodes passing through it, with each
+// pair counted once and paths split evenly where there are several. The
+// length of an edge is the inverse of its weight, so heavily weighted edges
+// are close.
+//
+// If samples is positive and less than the number of nodes, only paths from
+// that many sources chosen by rng are followed and the result is scaled up to
+// estimate the exact value. Otherwise every source is used.
+func (g *Graph) Betweenness(samples int, rng *rand.Rand) []float64 {
+	n := g.Len()
+	betweenness := make([]float64, n)
+
+	sources := rng.Perm(n)
+	scale := 0.5
+	if samples > 0 && samples < n {
+		sources = sources[:samples]
+		scale *= float64(n) / float64(samples)
+	}
+
+	b := newBrandes(n)
+	for _, s := range sources {
+		b.accumulate(g, s, betweenness)
+	}
+
+	for i := range betweenness {
+		betweenness[i] *= scale
+	}
+
+	return betweenness
+}
+
+// brandes holds the state of Brandes' algorithm, reused between sources.
+type brandes struct {
+	dist  []float64
+	sigma []float64
+	delta []float64
+	preds [][]int
+	order []int
+	seen  []bool
+	queue distanceQueue
+}
+
+func newBrandes(n int) *brandes {
+	return &brandes{
+		dist:  make([]float64, n),
+		sigma: make([]float64, n),
+		delta: make([]float64, n),
+		preds: make([][]int, n),
+		seen:  make([]bool, n),
+	}
+}
+
+// accumulate adds the dependencies of every node on paths from s to
+// betweenness.
+func (b *brandes) accumulate(g *Graph, s int, betweenness []float64) {
+	for i := range b.dist {
+		b.dist[i] = -1
+		b.sigma[i] = 0
+		b.delta[i] = 0
+		b.preds[i] = b.preds[i][:0]
+		b.seen[i] = false
+	}
+	b.order = b.order[:0]
+
+	b.dist[s] = 0
+	b.sigma[s] = 1
+	heap.Push(&b.queue, queued{node: s})
+
+	for b.queue.Len() > 0 {
+		q := heap.Pop(&b.queue).(queued)
+		v := q.node
+		if b.seen[v] {
+			continue
+		}
+		b.seen[v] = true
+		b.order = append(b.order, v)
+
+		for _, e := range g.edges[v] {
+			if e.Weight <= 0 {
+				continue
+			}
+			w := e.To
+			d := b.dist[v] + 1/e.Weight
+
+			switch {
+			case b.dist[w] < 0 || d < b.dist[w]*(1-distanceTolerance):
+				b.dist[w] = d
+				b.sigma[w] = b.sigma[v]
+				b.preds[w] = append(b.preds[w][:0], v)
+				heap.Push(&b.queue, queued{node: w, dist: d})
+			case !b.seen[w] && d <= b.dist[w]*(1+distanceTolerance):
+				b.sigma[w] += b.sigma[v]
+				b.preds[w] = append(b.preds[w], v)
+			}
+		}
+	}
+
+	for i := len(b.order) - 1; i >= 0; i-- {
+		w := b.order[i]
+		for _, v := range b.preds[w] {
+			b.delta[v] += b.sigma[v] / b.sigma[w] * (1 + b.delta[w])
+		}
+		if w != s {
+			betweenness[w] += b.delta[w]
+		}
+	}
+}
+
+// distanceTolerance is the relative difference below which path lengths are
+// treated as equal, since summing inverse weights isn't exact.
+const distanceTolerance = 1e-12
+
+type queued struct {
+	node int
+	dist float64
+}
+
+// distanceQueue is a min-heap of nodes by distance.
+type distanceQueue []queued
+
+func (q distanceQueue) Len() int           { return len(q) }
+func (q distanceQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q distanceQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *distanceQueue) Push(x any)        { *q = append(*q, x.(queued)) }
+func (q *distanceQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,34 @@
+package graph
+
+// Components labels each node with its connected component. Components are
+// numbered from the largest, starting at 0.
+func (g *Graph) Components() []int {
+	labels := make([]int, g.Len())
+	for i := range labels {
+		labels[i] = -1
+	}
+
+	var stack []int
+	component := 0
+	for start := range labels {
+		if labels[start] >= 0 {
+			continue
+		}
+
+		labels[start] = component
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range g.edges[v] {
+				if labels[e.To] < 0 {
+					labels[e.To] = component
+					stack = append(stack, e.To)
+				}
+			}
+		}
+		component++
+	}
+
+	return relabel(labels)
+}
@@ -0,0 +1,114 @@
+// Package graph analyses weighted undirected graphs, such as the network of
+// software mentioned in the same papers.
+package graph
+
+import (
+	"sort"
+)
+
+// Graph is a weighted undirected graph with named nodes.
+type Graph struct {
+	names []string
+	index map[string]int
+	edges [][]Edge
+}
+
+// Edge is an edge from a node to node To.
+type Edge struct {
+	To     int
+	Weight float64
+}
+
+func New() *Graph {
+	return &Graph{index: make(map[string]int)}
+}
+
+// Node returns the index of the node called name, adding it if the graph
+// doesn't have it yet.
+func (g *Graph) Node(name string) int {
+	if i, ok := g.index[name]; ok {
+		return i
+	}
+
+	i := len(g.names)
+	g.names = append(g.names, name)
+	g.index[name] = i
+	g.edges = append(g.edges, nil)
+
+	return i
+}
+
+// Name is the name of node i.
+func (g *Graph) Name(i int) string {
+	return g.names[i]
+}
+
+// Len is the number of nodes.
+func (g *Graph) Len() int {
+	return len(g.names)
+}
+
+// AddEdge adds an edge between nodes a and b. Self-loops are ignored.
+func (g *Graph) AddEdge(a, b int, weight float64) {
+	if a == b {
+		return
+	}
+
+	g.edges[a] = append(g.edges[a], Edge{To: b, Weight: weight})
+	g.edges[b] = append(g.edges[b], Edge{To: a, Weight: weight})
+}
+
+// Edges are the edges of node i.
+func (g *Graph) Edges(i int) []Edge {
+	return g.edges[i]
+}
+
+// Degree is the number of edges of node i.
+func (g *Graph) Degree(i int) int {
+	return len(g.edges[i])
+}
+
+// Strength is the total weight of the edges of node i.
+func (g *Graph) Strength(i int) float64 {
+	strength := 0.0
+	for _, e := range g.edges[i] {
+		strength += e.Weight
+	}
+	return strength
+}
+
+// relabel renumbers labels so that 0 is the label shared by the most nodes, 1
+// the next most, and so on. Ties go to the label of the lowest node.
+func relabel(labels []int) []int {
+	sizes := make(map[int]int)
+	first := make(map[int]int)
+	for i, label := range labels {
+		if _, ok := first[label]; !ok {
+			first[label] = i
+		}
+		sizes[label]++
+	}
+
+	order := make([]int, 0, len(sizes))
+	for label := range sizes {
+		order = append(order, label)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if sizes[order[i]] != sizes[order[j]] {
+			return sizes[order[i]] > sizes[order[j]]
+		}
+		return first[order[i]] < first[order[j]]
+	})
+
+	newLabel := make(map[int]int, len(order))
+	for i, label := range order {
+		newLabel[label] = i
+	}
+
+	relabeled := make([]int, len(labels))
+	for i, label := range labels {
+		relabeled[i] = newLabel[label]
+	}
+
+	return relabeled
+}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// twoCliques returns two cliques of five nodes, "a0" to "a4" and "b0" to "b4",
+// joined by an edge between "a4" and "b0", as well as a pair of nodes "x" and
+// "y" and an isolated node "z".
+func twoCliques() *Graph {
+	g := New()
+	for _, prefix := range []string{"a", "b"} {
+		for i := range 5 {
+			for j := range i {
+				g.AddEdge(g.Node(fmt.Sprint(prefix, i)), g.Node(fmt.Sprint(prefix, j)), 1)
+			}
+		}
+	}
+	g.AddEdge(g.Node("a4"), g.Node("b0"), 1)
+	g.AddEdge(g.Node("x"), g.Node("y"), 2)
+	g.Node("z")
+
+	return g
+}
+
+func TestGraph_Communities(t *testing.T) {
+	g := twoCliques()
+
+	for seed := range int64(10) {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			labels, modularity := g.Communities(1, rand.New(rand.NewSource(seed)))
+
+			for _, prefix := range []string{"a", "b"} {
+				for i := 1; i < 5; i++ {
+					if labels[g.Node(fmt.Sprint(prefix, i))] != labels[g.Node(prefix+"0")] {
+						t.Errorf("clique %q split across communities: %v", prefix, labels)
+					}
+				}
+			}
+			if labels[g.Node("a0")] == labels[g.Node("b0")] {
+				t.Errorf("cliques in the same community: %v", labels)
+			}
+			if labels[g.Node("x")] != labels[g.Node("y")] {
+				t.Errorf("pair split across communities: %v", labels)
+			}
+			if labels[g.Node("z")] == labels[g.Node("x")] || labels[g.Node("z")] == labels[g.Node("a0")] || labels[g.Node("z")] == labels[g.Node("b0")] {
+				t.Errorf("isolated node in a community with other nodes: %v", labels)
+			}
+
+			// The two cliques and the pair, plus the isolated node.
+			communities := 0
+			for _, label := range labels {
+				communities = max(communities, label+1)
+			}
+			if communities != 4 {
+				t.Errorf("got %d communities, want 4", communities)
+			}
+
+			if want := g.Modularity(labels, 1); math.Abs(modularity-want) > 1e-12 {
+				t.Errorf("got modularity %v, want %v", modularity, want)
+			}
+			if want := 0.5321361058601135; math.Abs(modularity-want) > 1e-9 {
+				t.Errorf("got modularity %v, want %v", modularity, want)
+			}
+		})
+	}
+}
+
+func TestGraph_Betweenness(t *testing.T) {
+	g := twoCliques()
+	betweenness := g.Betweenness(0, rand.New(rand.NewSource(1)))
+
+	// Every shortest path from the four other nodes of one clique to the five
+	// nodes of the other passes through both ends of the bridge, and no other
+	// shortest path has intermediate nodes.
+	for i, b := range betweenness {
+		want := 0.0
+		if name := g.Name(i); name == "a4" || name == "b0" {
+			want = 20
+		}
+		if math.Abs(b-want) > 1e-9 {
+			t.Errorf("got betweenness %v for %q, want %v", b, g.Name(i), want)
+		}
+	}
+}
+
+func TestGraph_PageRank(t *testing.T) {
+	g := twoCliques()
+	rank := g.PageRank(0.85)
+
+	sum := 0.0
+	for i, r := range rank {
+		if r <= 0 {
+			t.Errorf("got rank %v for %q, want positive", r, g.Name(i))
+		}
+		sum += r
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("got ranks summing to %v, want 1", sum)
+	}
+
+	// The ends of the bridge have the most weight in the cliques.
+	a4, a0 := rank[g.Node("a4")], rank[g.Node("a0")]
+	if a4 <= a0 {
+		t.Errorf("got rank %v for a bridge node, want more than %v", a4, a0)
+	}
+	if b0 := rank[g.Node("b0")]; math.Abs(a4-b0) > 1e-9 {
+		t.Errorf("got ranks %v and %v for the ends of the bridge, want equal", a4, b0)
+	}
+}
+
+func TestGraph_Components(t *testing.T) {
+	g := twoCliques()
+	labels := g.Components()
+
+	want := map[string]int{"a0": 0, "a4": 0, "b0": 0, "b4": 0, "x": 1, "y": 1, "z": 2}
+	for name, label := range want {
+		if got := labels[g.Node(name)]; got != label {
+			t.Errorf("got component %d for %q, want %d", got, name, label)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package graph
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// modularityTolerance is the smallest gain in modularity worth moving a node
+// for, so rounding errors can't make nodes move back and forth forever.
+const modularityTolerance = 1e-12
+
+// Communities finds communities of densely connected nodes with the Louvain
+// method, which greedily moves nodes between communities to increase
+// modularity and then merges each community into a single node, until no move
+// helps. Higher resolutions find smaller communities; 1 is standard
+// modularity. As in the Leiden algorithm, communities which end up
+// disconnected are split, so every community is connected.
+//
+// Nodes are visited in an order chosen by rng. Communities are numbered from
+// the largest, starting at 0, and are returned with their modularity.
+func (g *Graph) Communities(resolution float64, rng *rand.Rand) ([]int, float64) {
+	n := g.Len()
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = i
+	}
+
+	l := newLevel(g)
+	if l.total == 0 {
+		// Without edges every node is its own community.
+		return relabel(labels), 0
+	}
+
+	for {
+		communities, moved := l.moveNodes(resolution, rng)
+		if !moved {
+			break
+		}
+
+		for i, label := range labels {
+			labels[i] = communities[label]
+		}
+		l = l.aggregate(communities)
+	}
+
+	labels = relabel(g.splitDisconnected(labels))
+
+	return labels, g.Modularity(labels, resolution)
+}
+
+// Modularity is how much more weight lies within the communities given by
+// labels than expected if edges were placed at random, preserving each node's
+// strength.
+func (g *Graph) Modularity(labels []int, resolution float64) float64 {
+	total := 0.0
+	communities := 0
+	for i := range g.Len() {
+		total += g.Strength(i)
+		communities = max(communities, labels[i]+1)
+	}
+	if total == 0 {
+		return 0
+	}
+
+	internal := make([]float64, communities)
+	strength := make([]float64, communities)
+	for i := range g.Len() {
+		for _, e := range g.edges[i] {
+			strength[labels[i]] += e.Weight
+			if labels[e.To] == labels[i] {
+				internal[labels[i]] += e.Weight
+			}
+		}
+	}
+
+	q := 0.0
+	for c := range communities {
+		q += internal[c]/total - resolution*(strength[c]/total)*(strength[c]/total)
+	}
+
+	return q
+}
+
+// splitDisconnected gives each connected part of a community its own label.
+func (g *Graph) splitDisconnected(labels []int) []int {
+	split := make([]int, len(labels))
+	for i := range split {
+		split[i] = -1
+	}
+
+	var stack []int
+	next := 0
+	for start := range labels {
+		if split[start] >= 0 {
+			continue
+		}
+
+		split[start] = next
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range g.edges[v] {
+				if split[e.To] < 0 && labels[e.To] == labels[v] {
+					split[e.To] = next
+					stack = append(stack, e.To)
+				}
+			}
+		}
+		next++
+	}
+
+	return split
+}
+
+// level is a graph of communities merged into single nodes, whose edges within
+// a community have become self-loops.
+type level struct {
+	edges [][]Edge
+	// strength is the total weight of each node's edges, counting self-loops
+	// twice as they have both ends at the node.
+	strength []float64
+	// total is the sum of strengths, twice the total weight of edges.
+	total float64
+}
+
+func newLevel(g *Graph) *level {
+	l := &level{
+		edges:    g.edges,
+		strength: make([]float64, g.Len()),
+	}
+	for i := range g.Len() {
+		l.strength[i] = g.Strength(i)
+		l.total += l.strength[i]
+	}
+	return l
+}
+
+// moveNodes repeatedly moves each node to the neighbouring community which
+// most increases modularity, until no move does. It returns the community of
+// each node, numbered from 0, and whether any node moved.
+func (l *level) moveNodes(resolution float64, rng *rand.Rand) ([]int, bool) {
+	n := len(l.edges)
+	community := make([]int, n)
+	communityStrength := make([]float64, n)
+	for i := range n {
+		community[i] = i
+		communityStrength[i] = l.strength[i]
+	}
+
+	// weightTo is the weight of edges from the current node to each community,
+	// with touched listing the communities it has been set for.
+	weightTo := make([]float64, n)
+	var touched []int
+
+	order := rng.Perm(n)
+	moved := false
+	for {
+		movedThisPass := false
+		for _, i := range order {
+			for _, e := range l.edges[i] {
+				if e.To == i {
+					continue
+				}
+				c := community[e.To]
+				if weightTo[c] == 0 {
+					touched = append(touched, c)
+				}
+				weightTo[c] += e.Weight
+			}
+
+			current := community[i]
+			communityStrength[current] -= l.strength[i]
+
+			// The gain in modularity from joining community c, up to a factor
+			// common to every community.
+			gain := func(c int) float64 {
+				return weightTo[c] - resolution*communityStrength[c]*l.strength[i]/l.total
+			}
+
+			best := current
+			bestGain := gain(current)
+			for _, c := range touched {
+				if g := gain(c); g > bestGain+modularityTolerance {
+					best, bestGain = c, g
+				}
+			}
+
+			communityStrength[best] += l.strength[i]
+			if best != current {
+				community[i] = best
+				movedThisPass = true
+				moved = true
+			}
+
+			for _, c := range touched {
+				weightTo[c] = 0
+			}
+			touched = touched[:0]
+		}
+
+		if !movedThisPass {
+			break
+		}
+	}
+
+	// Number the communities from 0.
+	number := make(map[int]int)
+	for i, c := range community {
+		if _, ok := number[c]; !ok {
+			number[c] = len(number)
+		}
+		community[i] = number[c]
+	}
+
+	return community, moved
+}
+
+// aggregate merges each community of the level into a single node.
+func (l *level) aggregate(community []int) *level {
+	communities := 0
+	for _, c := range community {
+		communities = max(communities, c+1)
+	}
+
+	next := &level{
+		edges:    make([][]Edge, communities),
+		strength: make([]float64, communities),
+		total:    l.total,
+	}
+
+	weights := make([]map[int]float64, communities)
+	for c := range weights {
+		weights[c] = make(map[int]float64)
+	}
+
+	for i, edges := range l.edges {
+		c := community[i]
+		next.strength[c] += l.strength[i]
+		for _, e := range edges {
+			weights[c][community[e.To]] += e.Weight
+		}
+	}
+
+	for c, w := range weights {
+		for d, weight := range w {
+			next.edges[c] = append(next.edges[c], Edge{To: d, Weight: weight})
+		}
+		// Keep the order nodes are considered in independent of map order, so
+		// results only depend on rng.
+		sort.Slice(next.edges[c], func(i, j int) bool {
+			return next.edges[c][i].To < next.edges[c][j].To
+		})
+	}
+
+	return next
+}
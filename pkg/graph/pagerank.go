@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"math"
+)
+
+const (
+	pageRankTolerance     = 1e-10
+	pageRankMaxIterations = 1000
+)
+
+// PageRank is the weighted PageRank of each node: a random walk follows each
+// edge in proportion to its weight, and with probability 1-damping jumps to a
+// node chosen uniformly. Nodes without edges jump uniformly. The ranks sum to
+// one.
+func (g *Graph) PageRank(damping float64) []float64 {
+	n := g.Len()
+	if n == 0 {
+		return nil
+	}
+
+	strengths := make([]float64, n)
+	for i := range n {
+		strengths[i] = g.Strength(i)
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+
+	for range pageRankMaxIterations {
+		dangling := 0.0
+		for i, s := range strengths {
+			if s == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+
+		for i, s := range strengths {
+			if s == 0 {
+				continue
+			}
+			share := damping * rank[i] / s
+			for _, e := range g.edges[i] {
+				next[e.To] += share * e.Weight
+			}
+		}
+
+		change := 0.0
+		for i := range rank {
+			change += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+
+		if change < pageRankTolerance {
+			break
+		}
+	}
+
+	return rank
+}
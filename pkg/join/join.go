@@ -0,0 +1,171 @@
+// Package join pairs up records from two streams sorted by the same key, such
+// as shards sorted by paper UUID, without holding either stream in memory.
+package join
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+type Kind string
+
+const (
+	// Inner keeps only keys present in both streams.
+	Inner Kind = "inner"
+	// Left keeps every record of the left stream, paired with matching right
+	// records if there are any.
+	Left Kind = "left"
+	// FullOuter keeps every record of both streams.
+	FullOuter Kind = "full"
+)
+
+var (
+	ErrJoin     = errors.New("joining streams")
+	ErrUnsorted = errors.New("stream not sorted by key")
+)
+
+// Row is one output record of a join. At least one of HasLeft and HasRight is
+// true.
+type Row[L, R any] struct {
+	Key []byte
+
+	Left    L
+	HasLeft bool
+
+	Right    R
+	HasRight bool
+}
+
+// Join merges left and right, which must both be in ascending order of the
+// keys returned by leftKey and rightKey as compared by bytes.Compare. Keys are
+// compared within each stream as they are read, and the first out-of-order
+// key ends the join with ErrUnsorted.
+//
+// Records sharing a key are paired with every matching record of the other
+// stream. Only the right stream's records for the current key are held in
+// memory, so the smaller or more unique stream should be on the right.
+// Readers which reuse records between reads must not be used on the right.
+//
+// As with the pbl and jsonio readers, an io.EOF error ends a stream cleanly.
+func Join[L, R any](kind Kind, left iter.Seq2[L, error], leftKey func(L) ([]byte, error), right iter.Seq2[R, error], rightKey func(R) ([]byte, error)) iter.Seq2[Row[L, R], error] {
+	return func(yield func(Row[L, R], error) bool) {
+		switch kind {
+		case Inner, Left, FullOuter:
+		default:
+			yield(Row[L, R]{}, fmt.Errorf("%w: unknown join kind %q", ErrJoin, kind))
+			return
+		}
+
+		nextLeft, stopLeft := iter.Pull2(left)
+		defer stopLeft()
+		nextRight, stopRight := iter.Pull2(right)
+		defer stopRight()
+
+		l := &cursor[L]{name: "left", next: nextLeft, keyFn: leftKey}
+		r := &cursor[R]{name: "right", next: nextRight, keyFn: rightKey}
+
+		err := l.advance()
+		if err == nil {
+			err = r.advance()
+		}
+		if err != nil {
+			yield(Row[L, R]{}, err)
+			return
+		}
+
+		for l.ok || r.ok {
+			var c int
+			switch {
+			case !r.ok:
+				c = -1
+			case !l.ok:
+				c = 1
+			default:
+				c = bytes.Compare(l.key, r.key)
+			}
+
+			switch {
+			case c < 0:
+				if kind != Inner {
+					if !yield(Row[L, R]{Key: l.key, Left: l.cur, HasLeft: true}, nil) {
+						return
+					}
+				}
+				err = l.advance()
+			case c > 0:
+				if kind == FullOuter {
+					if !yield(Row[L, R]{Key: r.key, Right: r.cur, HasRight: true}, nil) {
+						return
+					}
+				}
+				err = r.advance()
+			default:
+				key := r.key
+				var group []R
+				for err == nil && r.ok && bytes.Equal(r.key, key) {
+					group = append(group, r.cur)
+					err = r.advance()
+				}
+
+				for err == nil && l.ok && bytes.Equal(l.key, key) {
+					for _, right := range group {
+						row := Row[L, R]{Key: key, Left: l.cur, HasLeft: true, Right: right, HasRight: true}
+						if !yield(row, nil) {
+							return
+						}
+					}
+					err = l.advance()
+				}
+			}
+
+			if err != nil {
+				yield(Row[L, R]{}, err)
+				return
+			}
+		}
+	}
+}
+
+// cursor is the current record of one stream being joined.
+type cursor[T any] struct {
+	name  string
+	next  func() (T, error, bool)
+	keyFn func(T) ([]byte, error)
+
+	ok  bool
+	cur T
+	key []byte
+}
+
+// advance reads the next record, setting ok to false at the end of the
+// stream.
+func (c *cursor[T]) advance() error {
+	prevKey := c.key
+
+	v, err, ok := c.next()
+	if !ok || errors.Is(err, io.EOF) {
+		c.ok = false
+		return nil
+	}
+	if err != nil {
+		c.ok = false
+		return fmt.Errorf("%w: reading %s stream: %w", ErrJoin, c.name, err)
+	}
+
+	key, err := c.keyFn(v)
+	if err != nil {
+		c.ok = false
+		return fmt.Errorf("%w: getting key from %s stream: %w", ErrJoin, c.name, err)
+	}
+
+	if prevKey != nil && bytes.Compare(key, prevKey) < 0 {
+		c.ok = false
+		return fmt.Errorf("%w: %w: %s stream key %x follows %x", ErrJoin, ErrUnsorted, c.name, key, prevKey)
+	}
+
+	c.cur, c.key, c.ok = v, key, true
+	return nil
+}
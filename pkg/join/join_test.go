@@ -0,0 +1,154 @@
+package join
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"iter"
+	"strings"
+	"testing"
+)
+
+// records streams names like "b2", keyed by their first letter.
+func records(names ...string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for _, name := range names {
+			if !yield(name, nil) {
+				return
+			}
+		}
+		// End with io.EOF as the pbl and jsonio readers do.
+		yield("", io.EOF)
+	}
+}
+
+func key(name string) ([]byte, error) {
+	return []byte(name[:1]), nil
+}
+
+// rows formats each row as "left|right", with "-" for a missing side.
+func rows(t *testing.T, seq iter.Seq2[Row[string, string], error]) ([]string, error) {
+	t.Helper()
+
+	var got []string
+	for row, err := range seq {
+		if err != nil {
+			return got, err
+		}
+
+		left, right := "-", "-"
+		if row.HasLeft {
+			left = row.Left
+		}
+		if row.HasRight {
+			right = row.Right
+		}
+		if k := string(row.Key); !strings.HasPrefix(left, k) && !strings.HasPrefix(right, k) {
+			t.Errorf("row %s|%s has key %q", left, right, k)
+		}
+		got = append(got, left+"|"+right)
+	}
+
+	return got, nil
+}
+
+func TestJoin(t *testing.T) {
+	tcs := []struct {
+		name  string
+		kind  Kind
+		left  []string
+		right []string
+		want  []string
+	}{{
+		name:  "inner with duplicates on both sides",
+		kind:  Inner,
+		left:  []string{"a1", "b1", "b2", "d1"},
+		right: []string{"b1", "b2", "c1", "d1"},
+		want:  []string{"b1|b1", "b1|b2", "b2|b1", "b2|b2", "d1|d1"},
+	}, {
+		name:  "left with duplicates on both sides",
+		kind:  Left,
+		left:  []string{"a1", "b1", "b2", "d1"},
+		right: []string{"b1", "b2", "c1", "d1"},
+		want:  []string{"a1|-", "b1|b1", "b1|b2", "b2|b1", "b2|b2", "d1|d1"},
+	}, {
+		name:  "full with duplicates on both sides",
+		kind:  FullOuter,
+		left:  []string{"a1", "b1", "b2", "d1"},
+		right: []string{"b1", "b2", "c1", "d1"},
+		want:  []string{"a1|-", "b1|b1", "b1|b2", "b2|b1", "b2|b2", "-|c1", "d1|d1"},
+	}, {
+		name:  "left with unmatched duplicates",
+		kind:  Left,
+		left:  []string{"a1", "a2", "c1"},
+		right: []string{"b1", "b2", "c1", "c2"},
+		want:  []string{"a1|-", "a2|-", "c1|c1", "c1|c2"},
+	}, {
+		name:  "full with unmatched duplicates",
+		kind:  FullOuter,
+		left:  []string{"a1", "a2", "c1"},
+		right: []string{"b1", "b2", "c1", "c2"},
+		want:  []string{"a1|-", "a2|-", "-|b1", "-|b2", "c1|c1", "c1|c2"},
+	}, {
+		name:  "inner with empty right",
+		kind:  Inner,
+		left:  []string{"a1", "b1"},
+		right: nil,
+		want:  nil,
+	}, {
+		name:  "full with empty left",
+		kind:  FullOuter,
+		left:  nil,
+		right: []string{"a1", "a2"},
+		want:  []string{"-|a1", "-|a2"},
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rows(t, Join(tc.kind, records(tc.left...), key, records(tc.right...), key))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestJoin_Unsorted(t *testing.T) {
+	tcs := []struct {
+		name  string
+		left  []string
+		right []string
+	}{{
+		name:  "left",
+		left:  []string{"a1", "c1", "b1"},
+		right: []string{"a1", "b1", "c1"},
+	}, {
+		name:  "right",
+		left:  []string{"a1", "b1", "c1"},
+		right: []string{"a1", "c1", "b1"},
+	}, {
+		name:  "right within a group of duplicates",
+		left:  []string{"b1"},
+		right: []string{"b1", "b2", "a1"},
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := rows(t, Join(FullOuter, records(tc.left...), key, records(tc.right...), key))
+			if !errors.Is(err, ErrUnsorted) {
+				t.Errorf("got error %v, want %v", err, ErrUnsorted)
+			}
+		})
+	}
+}
+
+func TestJoin_UnknownKind(t *testing.T) {
+	_, err := rows(t, Join("outer", records("a1"), key, records("a1"), key))
+	if !errors.Is(err, ErrJoin) {
+		t.Errorf("got error %v, want %v", err, ErrJoin)
+	}
+}
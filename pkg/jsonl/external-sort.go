@@ -0,0 +1,395 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"sort"
+)
+
+// entryOverhead approximates the bytes used to hold one buffered entry in
+// addition to its encoded JSON.
+const entryOverhead = 64
+
+// maxMergeRuns is the most runs merged at once, which bounds the number of
+// open files and read buffers while merging. Beyond this, runs are merged in
+// passes into fewer, longer runs.
+const maxMergeRuns = 128
+
+var ErrExternalSort = errors.New("external sort")
+
+// sortEntry is an entry encoded as JSON along with its sort key.
+type sortEntry struct {
+	key   []byte
+	value []byte
+}
+
+// externalSort sorts entries like memorySort, but holds at most approximately
+// MaxMemory bytes of entries in memory. Whenever the buffer fills, it is
+// sorted and spilled to a temporary file in TempDir, and the resulting runs
+// are merged as the returned sequence is consumed. If there are more than
+// maxMergeRuns runs, they are first merged in passes into longer runs. If
+// everything fits in memory, no temporary files are written. Entries with
+// equal keys keep their input order.
+//
+// Errors encountered while merging end the sequence early and are reported
+// by Err. Temporary files are removed once the sequence is exhausted or
+// iteration stops.
+func (s *Sorter) externalSort(seq iter.Seq2[*map[string]any, error], path []string, keyType KeyType) (iter.Seq2[[]byte, map[string]any], error) {
+	var runs []string
+	removeRuns := func() {
+		removeFiles(runs)
+	}
+
+	var buffer []sortEntry
+	bufferSize := int64(0)
+
+	for v, err := range seq {
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			removeRuns()
+			return nil, err
+		}
+
+		key, err := entryKey(*v, path, keyType)
+		if err != nil {
+			removeRuns()
+			return nil, err
+		}
+
+		value, err := json.Marshal(*v)
+		if err != nil {
+			removeRuns()
+			return nil, fmt.Errorf("%w: marshalling entry: %w", ErrExternalSort, err)
+		}
+
+		buffer = append(buffer, sortEntry{key: key, value: value})
+		bufferSize += int64(len(key)+len(value)) + entryOverhead
+
+		if bufferSize >= s.MaxMemory {
+			run, err := writeRun(buffer, s.TempDir)
+			if err != nil {
+				removeRuns()
+				return nil, err
+			}
+			runs = append(runs, run)
+
+			buffer = nil
+			bufferSize = 0
+		}
+	}
+
+	sortEntries(buffer)
+
+	if len(runs) == 0 {
+		// Everything fit in memory, so there is nothing to merge.
+		return func(yield func([]byte, map[string]any) bool) {
+			for _, entry := range buffer {
+				v, err := decodeEntry(entry.value)
+				if err != nil {
+					s.err = err
+					return
+				}
+				if !yield(entry.key, v) {
+					return
+				}
+			}
+		}, nil
+	}
+
+	// Spill the remainder so every run is merged the same way.
+	if len(buffer) > 0 {
+		run, err := writeRun(buffer, s.TempDir)
+		if err != nil {
+			removeRuns()
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	var err error
+	runs, err = mergePasses(runs, s.TempDir, maxMergeRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func([]byte, map[string]any) bool) {
+		defer removeRuns()
+		err := mergeRuns(runs, yield)
+		if err != nil {
+			s.err = err
+		}
+	}, nil
+}
+
+func sortEntries(entries []sortEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+}
+
+func decodeEntry(value []byte) (map[string]any, error) {
+	v := make(map[string]any)
+	err := json.Unmarshal(value, &v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unmarshalling entry: %w", ErrExternalSort, err)
+	}
+	return v, nil
+}
+
+// writeRun sorts entries and writes them to a new temporary run, returning
+// its path.
+func writeRun(entries []sortEntry, tempDir string) (string, error) {
+	sortEntries(entries)
+
+	return createRun(tempDir, func(write func(sortEntry) error) error {
+		for _, entry := range entries {
+			err := write(entry)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createRun writes the entries fill passes to write to a new temporary file,
+// returning its path. Each entry is written as the uvarint length of its key,
+// the key, the uvarint length of its JSON, and then the JSON itself.
+func createRun(tempDir string, fill func(write func(sortEntry) error) error) (string, error) {
+	file, err := os.CreateTemp(tempDir, "jsonl-sort-*.run")
+	if err != nil {
+		return "", fmt.Errorf("%w: creating run: %w", ErrExternalSort, err)
+	}
+
+	writer := bufio.NewWriter(file)
+	var header []byte
+	write := func(entry sortEntry) error {
+		header = binary.AppendUvarint(header[:0], uint64(len(entry.key)))
+		header = append(header, entry.key...)
+		header = binary.AppendUvarint(header, uint64(len(entry.value)))
+
+		_, err := writer.Write(header)
+		if err == nil {
+			_, err = writer.Write(entry.value)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: writing run %q: %w", ErrExternalSort, file.Name(), err)
+		}
+		return nil
+	}
+
+	err = fill(write)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+
+	err = writer.Flush()
+	if err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close()
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("%w: closing run %q: %w", ErrExternalSort, file.Name(), err)
+	}
+
+	return file.Name(), nil
+}
+
+// mergePasses merges consecutive groups of at most fanIn runs into single
+// runs, repeating until at most fanIn runs remain. Merged runs are removed.
+// Since groups are consecutive, entries with equal keys keep their input
+// order. On error, every run is removed.
+func mergePasses(runs []string, tempDir string, fanIn int) ([]string, error) {
+	for len(runs) > fanIn {
+		var merged []string
+		for start := 0; start < len(runs); start += fanIn {
+			group := runs[start:min(start+fanIn, len(runs))]
+
+			run, err := createRun(tempDir, func(write func(sortEntry) error) error {
+				var writeErr error
+				err := mergeEntries(group, func(entry sortEntry) bool {
+					writeErr = write(entry)
+					return writeErr == nil
+				})
+				if err != nil {
+					return err
+				}
+				return writeErr
+			})
+			if err != nil {
+				removeFiles(merged)
+				removeFiles(runs[start:])
+				return nil, err
+			}
+
+			removeFiles(group)
+			merged = append(merged, run)
+		}
+		runs = merged
+	}
+
+	return runs, nil
+}
+
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		_ = os.Remove(path)
+	}
+}
+
+// runReader reads the entries of one run in order.
+type runReader struct {
+	file   *os.File
+	reader *bufio.Reader
+
+	// index is the position of the run in the input, used to break ties.
+	index int
+	cur   sortEntry
+}
+
+func (r *runReader) next() (bool, error) {
+	keyLen, err := binary.ReadUvarint(r.reader)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: reading run %q: %w", ErrExternalSort, r.file.Name(), err)
+	}
+
+	// Allocate new slices as the previous entry may still be referenced.
+	key := make([]byte, keyLen)
+	_, err = io.ReadFull(r.reader, key)
+	if err != nil {
+		return false, fmt.Errorf("%w: reading run %q: %w", ErrExternalSort, r.file.Name(), err)
+	}
+
+	valueLen, err := binary.ReadUvarint(r.reader)
+	if err != nil {
+		return false, fmt.Errorf("%w: reading run %q: %w", ErrExternalSort, r.file.Name(), err)
+	}
+
+	value := make([]byte, valueLen)
+	_, err = io.ReadFull(r.reader, value)
+	if err != nil {
+		return false, fmt.Errorf("%w: reading run %q: %w", ErrExternalSort, r.file.Name(), err)
+	}
+
+	r.cur = sortEntry{key: key, value: value}
+
+	return true, nil
+}
+
+// runHeap orders runs by their current entry.
+type runHeap []*runReader
+
+func (h runHeap) Len() int { return len(h) }
+
+func (h runHeap) Less(i, j int) bool {
+	if c := bytes.Compare(h[i].cur.key, h[j].cur.key); c != 0 {
+		return c < 0
+	}
+	return h[i].index < h[j].index
+}
+
+func (h runHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *runHeap) Push(x any) { *h = append(*h, x.(*runReader)) }
+
+func (h *runHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// mergeRuns performs a k-way merge of the sorted runs, yielding each entry in
+// order.
+func mergeRuns(runs []string, yield func([]byte, map[string]any) bool) error {
+	var decodeErr error
+	err := mergeEntries(runs, func(entry sortEntry) bool {
+		v, err := decodeEntry(entry.value)
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+		return yield(entry.key, v)
+	})
+	if err != nil {
+		return err
+	}
+
+	return decodeErr
+}
+
+// mergeEntries performs a k-way merge of the sorted runs, yielding each
+// encoded entry in order. Every run is open at once, so callers must limit how
+// many they pass.
+func mergeEntries(runs []string, yield func(sortEntry) bool) error {
+	h := make(runHeap, 0, len(runs))
+	defer func() {
+		for _, r := range h {
+			_ = r.file.Close()
+		}
+	}()
+
+	for i, run := range runs {
+		file, err := os.Open(run)
+		if err != nil {
+			return fmt.Errorf("%w: opening run %q: %w", ErrExternalSort, run, err)
+		}
+
+		r := &runReader{
+			file:   file,
+			reader: bufio.NewReader(file),
+			index:  i,
+		}
+		ok, err := r.next()
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+		if !ok {
+			_ = file.Close()
+			continue
+		}
+
+		h = append(h, r)
+	}
+	heap.Init(&h)
+
+	for h.Len() > 0 {
+		r := h[0]
+
+		if !yield(r.cur) {
+			return nil
+		}
+
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			_ = r.file.Close()
+			heap.Pop(&h)
+		}
+	}
+
+	return nil
+}
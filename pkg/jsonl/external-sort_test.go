@@ -0,0 +1,123 @@
+package jsonl
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"testing"
+)
+
+// keyedEntries returns n entries with keys repeating every 50 entries, so
+// most keys are shared and the order of equal keys shows whether sorting is
+// stable.
+func keyedEntries(n int) iter.Seq2[*map[string]any, error] {
+	return func(yield func(*map[string]any, error) bool) {
+		for i := range n {
+			v := map[string]any{"k": fmt.Sprintf("%03d", (i*7)%50), "i": float64(i)}
+			if !yield(&v, nil) {
+				return
+			}
+		}
+	}
+}
+
+func checkSorted(t *testing.T, got []map[string]any, n int) {
+	t.Helper()
+
+	if len(got) != n {
+		t.Fatalf("got %d entries, want %d", len(got), n)
+	}
+	for j := 1; j < len(got); j++ {
+		prevKey, key := got[j-1]["k"].(string), got[j]["k"].(string)
+		if prevKey > key {
+			t.Fatalf("entry %d has key %q after %q", j, key, prevKey)
+		}
+		if prevKey == key && got[j-1]["i"].(float64) > got[j]["i"].(float64) {
+			t.Fatalf("entries with key %q out of input order: %v before %v", key, got[j-1]["i"], got[j]["i"])
+		}
+	}
+}
+
+func TestSorter_ExternalSort_ManyRuns(t *testing.T) {
+	tempDir := t.TempDir()
+	n := 3*maxMergeRuns + 1
+
+	// Every entry fills the buffer, so each is spilled to its own run and
+	// merging needs a pass before the final merge.
+	sorter := &Sorter{KeyPath: "k", KeyType: KeyString, MaxMemory: 1, TempDir: tempDir}
+	sorted, err := sorter.Sort(keyedEntries(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]any
+	for v := range sorted {
+		got = append(got, v)
+	}
+	if err := sorter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	checkSorted(t, got, n)
+
+	left, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("got %d temporary files left after sorting, want 0", len(left))
+	}
+}
+
+func TestMergePasses(t *testing.T) {
+	tempDir := t.TempDir()
+	n := 100
+
+	// Spill runs of three entries each.
+	var runs []string
+	var buffer []sortEntry
+	for v, err := range keyedEntries(n) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := entryKey(*v, []string{"k"}, KeyString)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buffer = append(buffer, sortEntry{key: key, value: []byte(fmt.Sprintf(`{"k":%q,"i":%v}`, (*v)["k"], (*v)["i"]))})
+		if len(buffer) == 3 || int((*v)["i"].(float64)) == n-1 {
+			run, err := writeRun(buffer, tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			runs = append(runs, run)
+			buffer = nil
+		}
+	}
+
+	const fanIn = 3
+	runs, err := mergePasses(runs, tempDir, fanIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) > fanIn {
+		t.Errorf("got %d runs after merging, want at most %d", len(runs), fanIn)
+	}
+
+	left, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != len(runs) {
+		t.Errorf("got %d temporary files, want only the %d merged runs", len(left), len(runs))
+	}
+
+	var got []map[string]any
+	err = mergeRuns(runs, func(_ []byte, v map[string]any) bool {
+		got = append(got, v)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSorted(t, got, n)
+}
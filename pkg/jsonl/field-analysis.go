@@ -3,7 +3,10 @@ package jsonl
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // MaxEnum is the largest number of unique values to track before not trying to
@@ -12,50 +15,404 @@ const MaxEnum = 20
 
 type Field interface {
 	Add(obj any) (Field, error)
+	// Merge combines the values seen by other into the field, as if they had
+	// been added to it. Either field may be modified and returned, so neither
+	// should be used afterward.
+	Merge(other Field) (Field, error)
 	String() string
+	Summary() Summary
 }
 
-// NullField represents a field which is never filled in.
-// Adding any object to a NullField returns a non-NullField.
-type NullField struct{}
+// MergeFields merges each field in from into the field at the same path in
+// into.
+func MergeFields(into, from map[string]Field) error {
+	for path, field := range from {
+		existing, exists := into[path]
+		if !exists {
+			into[path] = field
+			continue
+		}
 
-func (nf *NullField) Add(obj any) (Field, error) {
-	switch o := obj.(type) {
-	case bool:
-		var f Field = &BoolField{}
-		f, err := f.Add(o)
+		merged, err := existing.Merge(field)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("merging fields at %q: %w", path, err)
 		}
-		return f, nil
-	case float64:
-		var f Field = &NumberField{
-			Seen: make(map[float64]int),
+		into[path] = merged
+	}
+
+	return nil
+}
+
+// AddFields adds obj to the field at path, and then its keys or elements to
+// the fields at the paths beneath it, creating fields as needed. Objects
+// record which keys they have, so how often a key is missing is the count of
+// its parent less the count of the key.
+//
+// Paths are the keys leading to a value, each preceded by "." and followed by
+// "[]" for every array it is nested in, as in ".a.b[].c". The path of a
+// top-level value is "".
+func AddFields(path string, obj any, fields map[string]Field) error {
+	field := fields[path]
+	if field == nil {
+		field = &NullField{}
+	}
+
+	field, err := field.Add(obj)
+	if err != nil {
+		return fmt.Errorf("adding value at %q: %w", path, err)
+	}
+	fields[path] = field
+
+	switch o := obj.(type) {
+	case []any:
+		for _, v := range o {
+			err := AddFields(path+"[]", v, fields)
+			if err != nil {
+				return err
+			}
 		}
-		f, err := f.Add(o)
-		if err != nil {
-			return nil, err
+	case map[string]any:
+		for k, v := range o {
+			err := AddFields(path+"."+k, v, fields)
+			if err != nil {
+				return err
+			}
 		}
-		return f, nil
+	}
+
+	return nil
+}
+
+// Summary is a structured description of the values seen for a field.
+type Summary struct {
+	// Type is "null", "bool", "enum", "string", "object", "array", "mixed", or
+	// the smallest numeric type which holds every value seen, such as "uint8"
+	// or "float64".
+	Type string `json:"type"`
+	// Count is the number of non-null values seen.
+	Count int `json:"count"`
+	// Nulls is the number of null values seen.
+	Nulls int `json:"nulls"`
+
+	// Min and Max are set for numeric fields, and are the shortest and longest
+	// lengths of array fields.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+
+	// Values counts each distinct value, if there were at most MaxEnum of them.
+	Values map[string]int `json:"values,omitempty"`
+	// Keys counts the objects each key was present in, for object fields.
+	Keys map[string]int `json:"keys,omitempty"`
+	// Lengths counts the arrays of each length, for array fields.
+	Lengths map[int]int `json:"lengths,omitempty"`
+	// Types summarizes the values of each kind, for mixed fields.
+	Types map[string]Summary `json:"types,omitempty"`
+
+	// Distinct is the number of distinct values of numeric and string fields.
+	// Exact for enums, and otherwise estimated.
+	Distinct uint64 `json:"distinct,omitempty"`
+	// Quantiles are estimated percentiles of numeric fields, keyed like "p50".
+	Quantiles map[string]float64 `json:"quantiles,omitempty"`
+	// Top are the most common values of numeric and string fields with too
+	// many values to be enums, with estimated counts.
+	Top []ValueCount `json:"top,omitempty"`
+
+	// MinLength, MaxLength, and MeanLength are the lengths in characters of
+	// string fields.
+	MinLength  *int     `json:"minLength,omitempty"`
+	MaxLength  *int     `json:"maxLength,omitempty"`
+	MeanLength *float64 `json:"meanLength,omitempty"`
+}
+
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// TopN is the number of most common values to report.
+const TopN = 10
+
+// Percentiles are the quantiles of numeric fields to report.
+var Percentiles = []int{1, 5, 25, 50, 75, 95, 99}
+
+const (
+	kindNull   = "null"
+	kindBool   = "bool"
+	kindNumber = "number"
+	kindString = "string"
+	kindObject = "object"
+	kindArray  = "array"
+)
+
+// kindOf is the JSON type of obj as decoded by encoding/json.
+func kindOf(obj any) string {
+	switch obj.(type) {
+	case nil:
+		return kindNull
+	case bool:
+		return kindBool
+	case float64:
+		return kindNumber
 	case string:
-		var f Field = &StringField{
-			Seen: make(map[string]int),
-		}
-		f, err := f.Add(o)
+		return kindString
+	case map[string]any:
+		return kindObject
+	case []any:
+		return kindArray
+	default:
+		return ""
+	}
+}
+
+// fieldKind is the kind of values held by f, or "" for MixedField.
+func fieldKind(f Field) string {
+	switch f.(type) {
+	case *NullField:
+		return kindNull
+	case *BoolField:
+		return kindBool
+	case *NumberField:
+		return kindNumber
+	case *StringField:
+		return kindString
+	case *ObjectField:
+		return kindObject
+	case *ArrayField:
+		return kindArray
+	default:
+		return ""
+	}
+}
+
+// newField returns an empty field for values of the same type as obj, which
+// has already seen nulls null values.
+func newField(obj any, nulls int) (Field, error) {
+	switch kindOf(obj) {
+	case kindBool:
+		return &BoolField{Nulls: nulls}, nil
+	case kindNumber:
+		return &NumberField{
+			Nulls:    nulls,
+			Seen:     make(map[float64]int),
+			Distinct: &HyperLogLog{},
+			Top:      NewSpaceSaving[float64](TopCapacity),
+			Digest:   NewTDigest(DigestCompression),
+		}, nil
+	case kindString:
+		return &StringField{
+			Nulls:    nulls,
+			Seen:     make(map[string]int),
+			Distinct: &HyperLogLog{},
+			Top:      NewSpaceSaving[string](TopCapacity),
+		}, nil
+	case kindObject:
+		return &ObjectField{
+			Nulls: nulls,
+			Keys:  make(map[string]int),
+		}, nil
+	case kindArray:
+		return &ArrayField{
+			Nulls:   nulls,
+			Lengths: make(map[int]int),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown type %T", obj)
+	}
+}
+
+// NullField represents a field which is never filled in.
+// Adding any non-null object to a NullField returns a non-NullField.
+type NullField struct {
+	Nulls int
+}
+
+func (nf *NullField) Add(obj any) (Field, error) {
+	if obj == nil {
+		nf.Nulls++
+		return nf, nil
+	}
+
+	f, err := newField(obj, nf.Nulls)
+	if err != nil {
+		return nil, fmt.Errorf("%w added to %T", err, nf)
+	}
+	return f.Add(obj)
+}
+
+func (nf *NullField) Merge(other Field) (Field, error) {
+	switch o := other.(type) {
+	case *NullField:
+		nf.Nulls += o.Nulls
+		return nf, nil
+	default:
+		// Every other field counts nulls itself.
+		return other.Merge(nf)
+	}
+}
+
+func (nf *NullField) String() string {
+	return fmt.Sprintf("null;%d", nf.Nulls)
+}
+
+func (nf *NullField) Summary() Summary {
+	return Summary{Type: "null", Nulls: nf.Nulls}
+}
+
+// MixedField is a field which has held values of more than one type, such as
+// both strings and numbers.
+type MixedField struct {
+	Nulls int
+	// Fields holds the values seen of each kind, such as "string" or "number".
+	Fields map[string]Field
+}
+
+// mix returns a MixedField holding the values of f, which are all of kind,
+// and obj.
+func mix(f Field, kind string, obj any) (Field, error) {
+	m := &MixedField{
+		Nulls:  takeNulls(f),
+		Fields: map[string]Field{kind: f},
+	}
+	return m.Add(obj)
+}
+
+// takeNulls resets the count of nulls in f and returns it. A MixedField holds
+// all the nulls itself so that it doesn't matter which field saw them.
+func takeNulls(f Field) int {
+	var nulls *int
+	switch field := f.(type) {
+	case *NullField:
+		nulls = &field.Nulls
+	case *BoolField:
+		nulls = &field.Nulls
+	case *NumberField:
+		nulls = &field.Nulls
+	case *StringField:
+		nulls = &field.Nulls
+	case *ObjectField:
+		nulls = &field.Nulls
+	case *ArrayField:
+		nulls = &field.Nulls
+	case *MixedField:
+		nulls = &field.Nulls
+	default:
+		return 0
+	}
+
+	n := *nulls
+	*nulls = 0
+	return n
+}
+
+func (f *MixedField) Add(obj any) (Field, error) {
+	if obj == nil {
+		f.Nulls++
+		return f, nil
+	}
+
+	kind := kindOf(obj)
+	field, exists := f.Fields[kind]
+	if !exists {
+		var err error
+		field, err = newField(obj, 0)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w added to %T", err, f)
+		}
+	}
+
+	field, err := field.Add(obj)
+	if err != nil {
+		return nil, err
+	}
+	f.Fields[kind] = field
+
+	return f, nil
+}
+
+// mixFields returns a MixedField holding the values of f, which are all of
+// kind, and other.
+func mixFields(f Field, kind string, other Field) (Field, error) {
+	m := &MixedField{
+		Nulls:  takeNulls(f),
+		Fields: map[string]Field{kind: f},
+	}
+	return m.Merge(other)
+}
+
+func (f *MixedField) Merge(other Field) (Field, error) {
+	switch o := other.(type) {
+	case *NullField:
+		f.Nulls += o.Nulls
+		return f, nil
+	case *MixedField:
+		f.Nulls += o.Nulls
+		for _, field := range o.Fields {
+			_, err := f.Merge(field)
+			if err != nil {
+				return nil, err
+			}
 		}
 		return f, nil
-	default:
-		return nil, fmt.Errorf("unknown type %T added to %T", o, nf)
 	}
+
+	kind := fieldKind(other)
+	if kind == "" {
+		return nil, fmt.Errorf("unknown field %T merged into %T", other, f)
+	}
+	f.Nulls += takeNulls(other)
+
+	existing, exists := f.Fields[kind]
+	if !exists {
+		f.Fields[kind] = other
+		return f, nil
+	}
+
+	merged, err := existing.Merge(other)
+	if err != nil {
+		return nil, err
+	}
+	f.Fields[kind] = merged
+
+	return f, nil
 }
 
-func (nf *NullField) String() string {
-	return "null"
+func (f *MixedField) kinds() []string {
+	kinds := make([]string, 0, len(f.Fields))
+	for kind := range f.Fields {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func (f *MixedField) String() string {
+	result := strings.Builder{}
+	result.WriteString(fmt.Sprintf("mixed;%d", f.Nulls))
+	for _, kind := range f.kinds() {
+		result.WriteString(fmt.Sprintf("|%s", f.Fields[kind]))
+	}
+
+	return result.String()
+}
+
+func (f *MixedField) Summary() Summary {
+	summary := Summary{
+		Type:  "mixed",
+		Nulls: f.Nulls,
+		Types: make(map[string]Summary, len(f.Fields)),
+	}
+
+	for kind, field := range f.Fields {
+		fieldSummary := field.Summary()
+		summary.Count += fieldSummary.Count
+		summary.Types[kind] = fieldSummary
+	}
+
+	return summary
 }
 
 type BoolField struct {
+	Nulls int
 	True  int
 	False int
 }
@@ -69,8 +426,26 @@ func (f *BoolField) Add(obj any) (Field, error) {
 			f.False++
 		}
 		return f, nil
+	case nil:
+		f.Nulls++
+		return f, nil
 	default:
-		return nil, fmt.Errorf("unknown type %T added to %T", o, f)
+		return mix(f, kindBool, o)
+	}
+}
+
+func (f *BoolField) Merge(other Field) (Field, error) {
+	switch o := other.(type) {
+	case *BoolField:
+		f.True += o.True
+		f.False += o.False
+		f.Nulls += o.Nulls
+		return f, nil
+	case *NullField:
+		f.Nulls += o.Nulls
+		return f, nil
+	default:
+		return mixFields(f, kindBool, other)
 	}
 }
 
@@ -78,19 +453,39 @@ func (f *BoolField) String() string {
 	return fmt.Sprintf("true:%d;false:%d", f.True, f.False)
 }
 
+func (f *BoolField) Summary() Summary {
+	return Summary{
+		Type:  "bool",
+		Count: f.True + f.False,
+		Nulls: f.Nulls,
+		Values: map[string]int{
+			"true":  f.True,
+			"false": f.False,
+		},
+	}
+}
+
 type NumberType string
 
 type NumberField struct {
 	Integral bool
 	Float32  bool
 
+	Count int
+	Nulls int
+
 	Min, Max float64
 	Seen     map[float64]int
+
+	Distinct *HyperLogLog
+	Top      *SpaceSaving[float64]
+	Digest   *TDigest
 }
 
 func (f *NumberField) Add(obj any) (Field, error) {
 	switch o := obj.(type) {
 	case float64:
+		f.Count++
 		if len(f.Seen) > 0 {
 			f.Integral = f.Integral && isIntegral(o)
 			f.Float32 = f.Float32 && isFloat32(o)
@@ -111,9 +506,57 @@ func (f *NumberField) Add(obj any) (Field, error) {
 		if len(f.Seen) <= MaxEnum {
 			f.Seen[o]++
 		}
+		f.Distinct.Add(hashFloat(o))
+		f.Top.Add(o)
+		f.Digest.Add(o)
+		return f, nil
+	case nil:
+		f.Nulls++
+		return f, nil
+	default:
+		return mix(f, kindNumber, o)
+	}
+}
+
+func (f *NumberField) Merge(other Field) (Field, error) {
+	switch o := other.(type) {
+	case *NumberField:
+		switch {
+		case o.Count == 0:
+		case f.Count == 0:
+			f.Integral, f.Float32 = o.Integral, o.Float32
+			f.Min, f.Max = o.Min, o.Max
+		default:
+			f.Integral = f.Integral && o.Integral
+			f.Float32 = f.Float32 && o.Float32
+			f.Min = min(f.Min, o.Min)
+			f.Max = max(f.Max, o.Max)
+		}
+
+		f.Count += o.Count
+		f.Nulls += o.Nulls
+		mergeSeen(f.Seen, o.Seen)
+		f.Distinct.Merge(o.Distinct)
+		f.Top.Merge(o.Top)
+		f.Digest.Merge(o.Digest)
+		return f, nil
+	case *NullField:
+		f.Nulls += o.Nulls
 		return f, nil
 	default:
-		return nil, fmt.Errorf("unknown type %T added to %T", o, f)
+		return mixFields(f, kindNumber, other)
+	}
+}
+
+// mergeSeen adds the counts of values in from to into. As with Add, values
+// stop being tracked once there are more than MaxEnum of them, so the result
+// is an enum exactly when the values seen in total would have been.
+func mergeSeen[K comparable](into, from map[K]int) {
+	for k, v := range from {
+		_, exists := into[k]
+		if exists || len(into) <= MaxEnum {
+			into[k] += v
+		}
 	}
 }
 
@@ -171,42 +614,47 @@ func isFloat32(f float64) bool {
 	//return len(formatted) <= 7
 }
 
-func (f *NumberField) String() string {
-	result := strings.Builder{}
+// Type is the smallest numeric type which holds every value seen.
+func (f *NumberField) Type() string {
 	if f.Integral {
 		if f.Min < 0 {
-			if f.Max <= math.MaxInt8 {
-				result.WriteString("int8")
-			} else if f.Max <= math.MaxInt16 {
-				result.WriteString("int16")
-			} else if f.Max <= math.MaxInt32 {
-				result.WriteString("int32")
+			if f.Min >= math.MinInt8 && f.Max <= math.MaxInt8 {
+				return "int8"
+			} else if f.Min >= math.MinInt16 && f.Max <= math.MaxInt16 {
+				return "int16"
+			} else if f.Min >= math.MinInt32 && f.Max <= math.MaxInt32 {
+				return "int32"
 			} else {
-				result.WriteString("int64")
+				return "int64"
 			}
 		} else {
 			if f.Max <= math.MaxUint8 {
-				result.WriteString("uint8")
+				return "uint8"
 			} else if f.Max <= math.MaxUint16 {
-				result.WriteString("uint16")
+				return "uint16"
 			} else if f.Max <= math.MaxUint32 {
-				result.WriteString("uint32")
+				return "uint32"
 			} else {
-				result.WriteString("uint64")
+				return "uint64"
 			}
 		}
 	} else {
 		if f.Float32 {
-			result.WriteString("float32")
+			return "float32"
 		} else {
-			result.WriteString("float64")
+			return "float64"
 		}
 	}
+}
+
+func (f *NumberField) String() string {
+	result := strings.Builder{}
+	result.WriteString(f.Type())
 	result.WriteString(";")
 	if f.Integral {
-		result.WriteString(fmt.Sprintf("%d;%d", int(f.Min), int(f.Max)))
+		result.WriteString(fmt.Sprintf("%d;%d;", int(f.Min), int(f.Max)))
 	} else {
-		result.WriteString(fmt.Sprintf("%f;%f", f.Min, f.Max))
+		result.WriteString(fmt.Sprintf("%f;%f;", f.Min, f.Max))
 	}
 
 	if len(f.Seen) <= MaxEnum {
@@ -222,19 +670,105 @@ func (f *NumberField) String() string {
 	return result.String()
 }
 
+func (f *NumberField) Summary() Summary {
+	minValue, maxValue := f.Min, f.Max
+	summary := Summary{
+		Type:  f.Type(),
+		Count: f.Count,
+		Nulls: f.Nulls,
+		Min:   &minValue,
+		Max:   &maxValue,
+	}
+
+	if len(f.Seen) <= MaxEnum {
+		summary.Distinct = uint64(len(f.Seen))
+		summary.Values = make(map[string]int, len(f.Seen))
+		for k, v := range f.Seen {
+			summary.Values[strconv.FormatFloat(k, 'f', -1, 64)] = v
+		}
+	} else {
+		summary.Distinct = f.Distinct.Estimate()
+		for _, k := range f.Top.Top(TopN) {
+			summary.Top = append(summary.Top, ValueCount{
+				Value: strconv.FormatFloat(k, 'f', -1, 64),
+				Count: f.Top.Counts[k].Count,
+			})
+		}
+	}
+
+	if f.Count > 0 {
+		summary.Quantiles = make(map[string]float64, len(Percentiles))
+		for _, p := range Percentiles {
+			summary.Quantiles[fmt.Sprintf("p%d", p)] = f.Digest.Quantile(float64(p) / 100)
+		}
+	}
+
+	return summary
+}
+
 type StringField struct {
-	Seen map[string]int
+	Count int
+	Nulls int
+	Seen  map[string]int
+
+	Distinct *HyperLogLog
+	Top      *SpaceSaving[string]
+
+	// Lengths of the strings seen, in characters.
+	MinLength, MaxLength, TotalLength int
 }
 
 func (f *StringField) Add(obj any) (Field, error) {
 	switch o := obj.(type) {
 	case string:
+		f.Count++
 		if len(f.Seen) <= MaxEnum {
 			f.Seen[o]++
 		}
+		f.Distinct.Add(hashString(o))
+		f.Top.Add(o)
+
+		length := utf8.RuneCountInString(o)
+		if f.Count == 1 {
+			f.MinLength, f.MaxLength = length, length
+		} else {
+			f.MinLength = min(f.MinLength, length)
+			f.MaxLength = max(f.MaxLength, length)
+		}
+		f.TotalLength += length
+		return f, nil
+	case nil:
+		f.Nulls++
+		return f, nil
+	default:
+		return mix(f, kindString, o)
+	}
+}
+
+func (f *StringField) Merge(other Field) (Field, error) {
+	switch o := other.(type) {
+	case *StringField:
+		switch {
+		case o.Count == 0:
+		case f.Count == 0:
+			f.MinLength, f.MaxLength = o.MinLength, o.MaxLength
+		default:
+			f.MinLength = min(f.MinLength, o.MinLength)
+			f.MaxLength = max(f.MaxLength, o.MaxLength)
+		}
+		f.TotalLength += o.TotalLength
+
+		f.Count += o.Count
+		f.Nulls += o.Nulls
+		mergeSeen(f.Seen, o.Seen)
+		f.Distinct.Merge(o.Distinct)
+		f.Top.Merge(o.Top)
+		return f, nil
+	case *NullField:
+		f.Nulls += o.Nulls
 		return f, nil
 	default:
-		return nil, fmt.Errorf("unknown type %T added to %T", o, f)
+		return mixFields(f, kindString, other)
 	}
 }
 
@@ -246,8 +780,195 @@ func (f *StringField) String() string {
 			result.WriteString(fmt.Sprintf("%s:%d;", k, v))
 		}
 	} else {
-		result.WriteString("string;")
+		result.WriteString(fmt.Sprintf("string;~%d;", f.Distinct.Estimate()))
 	}
 
 	return result.String()
 }
+
+func (f *StringField) Summary() Summary {
+	summary := Summary{
+		Type:  "string",
+		Count: f.Count,
+		Nulls: f.Nulls,
+	}
+
+	if len(f.Seen) <= MaxEnum {
+		summary.Type = "enum"
+		summary.Distinct = uint64(len(f.Seen))
+		summary.Values = make(map[string]int, len(f.Seen))
+		for k, v := range f.Seen {
+			summary.Values[k] = v
+		}
+	} else {
+		summary.Distinct = f.Distinct.Estimate()
+		for _, k := range f.Top.Top(TopN) {
+			summary.Top = append(summary.Top, ValueCount{Value: k, Count: f.Top.Counts[k].Count})
+		}
+	}
+
+	if f.Count > 0 {
+		minLength, maxLength := f.MinLength, f.MaxLength
+		meanLength := float64(f.TotalLength) / float64(f.Count)
+		summary.MinLength = &minLength
+		summary.MaxLength = &maxLength
+		summary.MeanLength = &meanLength
+	}
+
+	return summary
+}
+
+// ObjectField counts how often each key is present in the objects at a path.
+// The values of keys are tracked by their own fields.
+type ObjectField struct {
+	Count int
+	Nulls int
+	Keys  map[string]int
+}
+
+func (f *ObjectField) Add(obj any) (Field, error) {
+	switch o := obj.(type) {
+	case map[string]any:
+		f.Count++
+		for k := range o {
+			f.Keys[k]++
+		}
+		return f, nil
+	case nil:
+		f.Nulls++
+		return f, nil
+	default:
+		return mix(f, kindObject, o)
+	}
+}
+
+func (f *ObjectField) Merge(other Field) (Field, error) {
+	switch o := other.(type) {
+	case *ObjectField:
+		f.Count += o.Count
+		f.Nulls += o.Nulls
+		for k, v := range o.Keys {
+			f.Keys[k] += v
+		}
+		return f, nil
+	case *NullField:
+		f.Nulls += o.Nulls
+		return f, nil
+	default:
+		return mixFields(f, kindObject, other)
+	}
+}
+
+func (f *ObjectField) String() string {
+	keys := make([]string, 0, len(f.Keys))
+	for k := range f.Keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := strings.Builder{}
+	result.WriteString(fmt.Sprintf("object;%d;", f.Count))
+	for _, k := range keys {
+		result.WriteString(fmt.Sprintf("%s:%d;", k, f.Keys[k]))
+	}
+
+	return result.String()
+}
+
+func (f *ObjectField) Summary() Summary {
+	summary := Summary{
+		Type:  "object",
+		Count: f.Count,
+		Nulls: f.Nulls,
+		Keys:  make(map[string]int, len(f.Keys)),
+	}
+	for k, v := range f.Keys {
+		summary.Keys[k] = v
+	}
+
+	return summary
+}
+
+// ArrayField is the distribution of lengths of the arrays at a path. The
+// elements are tracked by the field at the path followed by "[]".
+type ArrayField struct {
+	Count   int
+	Nulls   int
+	Lengths map[int]int
+}
+
+func (f *ArrayField) Add(obj any) (Field, error) {
+	switch o := obj.(type) {
+	case []any:
+		f.Count++
+		f.Lengths[len(o)]++
+		return f, nil
+	case nil:
+		f.Nulls++
+		return f, nil
+	default:
+		return mix(f, kindArray, o)
+	}
+}
+
+func (f *ArrayField) Merge(other Field) (Field, error) {
+	switch o := other.(type) {
+	case *ArrayField:
+		f.Count += o.Count
+		f.Nulls += o.Nulls
+		for length, n := range o.Lengths {
+			f.Lengths[length] += n
+		}
+		return f, nil
+	case *NullField:
+		f.Nulls += o.Nulls
+		return f, nil
+	default:
+		return mixFields(f, kindArray, other)
+	}
+}
+
+func (f *ArrayField) lengths() []int {
+	lengths := make([]int, 0, len(f.Lengths))
+	for length := range f.Lengths {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+	return lengths
+}
+
+func (f *ArrayField) String() string {
+	lengths := f.lengths()
+
+	result := strings.Builder{}
+	result.WriteString("array;")
+	if len(lengths) > 0 {
+		result.WriteString(fmt.Sprintf("%d;%d;", lengths[0], lengths[len(lengths)-1]))
+	}
+	for _, length := range lengths {
+		result.WriteString(fmt.Sprintf("%d:%d;", length, f.Lengths[length]))
+	}
+
+	return result.String()
+}
+
+func (f *ArrayField) Summary() Summary {
+	summary := Summary{
+		Type:    "array",
+		Count:   f.Count,
+		Nulls:   f.Nulls,
+		Lengths: make(map[int]int, len(f.Lengths)),
+	}
+	for length, n := range f.Lengths {
+		summary.Lengths[length] = n
+	}
+
+	lengths := f.lengths()
+	if len(lengths) > 0 {
+		minLength, maxLength := float64(lengths[0]), float64(lengths[len(lengths)-1])
+		summary.Min = &minLength
+		summary.Max = &maxLength
+	}
+
+	return summary
+}
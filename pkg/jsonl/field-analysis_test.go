@@ -0,0 +1,130 @@
+package jsonl
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"math/rand"
+	"testing"
+)
+
+// testRecord is a record with fields of every kind, including a field which
+// is a number in some records and a string in others, a field which is null
+// until late in the input, and objects in arrays.
+func testRecord(rng *rand.Rand, i int) map[string]any {
+	kinds := []string{"software", "dataset", "library", "service"}
+
+	record := map[string]any{
+		"id":    float64(i),
+		"score": rng.NormFloat64(),
+		"kind":  kinds[rng.Intn(len(kinds))],
+		"name":  fmt.Sprintf("name-%d", rng.Intn(200)),
+		"used":  rng.Intn(2) == 0,
+		"meta": map[string]any{
+			"offset": float64(rng.Intn(1000) - 100),
+		},
+	}
+
+	if i%3 == 0 {
+		record["mixed"] = "n/a"
+	} else {
+		record["mixed"] = float64(i)
+	}
+
+	if i < 150 {
+		record["late"] = nil
+	} else {
+		record["late"] = float64(rng.Intn(5))
+	}
+
+	if rng.Intn(4) > 0 {
+		record["meta"].(map[string]any)["note"] = fmt.Sprintf("%x", rng.Intn(1<<16))
+	}
+
+	var mentions []any
+	for range rng.Intn(4) {
+		mentions = append(mentions, map[string]any{
+			"start": float64(rng.Intn(5000)),
+			"tags":  []any{kinds[rng.Intn(len(kinds))]},
+		})
+	}
+	record["mentions"] = mentions
+
+	return record
+}
+
+// TestMergeFields checks that merging the fields of parts of the input, in
+// order, summarizes the input the same way as one pass over all of it, for
+// everything except estimated quantiles and most common values.
+func TestMergeFields(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var records []map[string]any
+	for i := range 400 {
+		records = append(records, testRecord(rng, i))
+	}
+
+	// Uneven parts, including an empty one and one where "late" is only null.
+	bounds := []int{0, 10, 10, 120, 290, 400}
+
+	whole := make(map[string]Field)
+	for _, record := range records {
+		err := AddFields("", record, whole)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	merged := make(map[string]Field)
+	for i := 1; i < len(bounds); i++ {
+		part := make(map[string]Field)
+		for _, record := range records[bounds[i-1]:bounds[i]] {
+			err := AddFields("", record, part)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := MergeFields(merged, part)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff(keys(whole), keys(merged)); diff != "" {
+		t.Fatalf("merging found different paths:\n%s", diff)
+	}
+
+	for path, field := range whole {
+		want := field.Summary()
+		got := merged[path].Summary()
+
+		if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Summary{}, "Quantiles", "Top")); diff != "" {
+			t.Errorf("summary of %q differs after merging:\n%s", path, diff)
+		}
+	}
+
+	// Check the input exercised each kind of field.
+	for path, want := range map[string]string{
+		".kind":              "enum",
+		".name":              "string",
+		".used":              "bool",
+		".meta":              "object",
+		".meta.offset":       "int16",
+		".mixed":             "mixed",
+		".late":              "uint8",
+		".mentions":          "array",
+		".mentions[].tags[]": "enum",
+	} {
+		if got := whole[path].Summary().Type; got != want {
+			t.Errorf("got type %q for %q, want %q", got, path, want)
+		}
+	}
+}
+
+func keys(fields map[string]Field) map[string]bool {
+	result := make(map[string]bool)
+	for path := range fields {
+		result[path] = true
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package jsonl
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// hllPrecision is the number of bits of each hash used to pick a register.
+// 2^12 registers gives a standard error of about 1.6%.
+const hllPrecision = 12
+
+// HyperLogLog estimates the number of distinct values added to it in constant
+// space.
+type HyperLogLog struct {
+	Registers [1 << hllPrecision]uint8
+}
+
+// Add records a value by its 64-bit hash, such as from hashString.
+func (h *HyperLogLog) Add(hash uint64) {
+	i := hash >> (64 - hllPrecision)
+
+	// Count leading zeros of the remaining bits, guarding against all zeroes.
+	w := hash<<hllPrecision | 1<<(hllPrecision-1)
+	rank := uint8(bits.LeadingZeros64(w) + 1)
+
+	if rank > h.Registers[i] {
+		h.Registers[i] = rank
+	}
+}
+
+// Merge makes h estimate the distinct values added to either h or other.
+func (h *HyperLogLog) Merge(other *HyperLogLog) {
+	for i, r := range other.Registers {
+		if r > h.Registers[i] {
+			h.Registers[i] = r
+		}
+	}
+}
+
+// Estimate is the approximate number of distinct values added.
+func (h *HyperLogLog) Estimate() uint64 {
+	m := float64(len(h.Registers))
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.Registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+
+	// Linear counting is more accurate for small cardinalities.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+// hashString hashes s for HyperLogLog. Hashes are the same between runs so
+// estimates are reproducible.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return mix64(h.Sum64())
+}
+
+func hashFloat(f float64) uint64 {
+	return mix64(math.Float64bits(f))
+}
+
+// mix64 is the MurmurHash3 finalizer, which spreads FNV's weak low bits across
+// the whole hash.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
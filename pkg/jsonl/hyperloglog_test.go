@@ -0,0 +1,68 @@
+package jsonl
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// hllTolerance is the relative error allowed, about three standard errors.
+const hllTolerance = 0.05
+
+func TestHyperLogLog_Estimate(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 1000, 10_000, 100_000, 1_000_000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			h := &HyperLogLog{}
+			for i := range n {
+				h.Add(hashString(fmt.Sprintf("value-%d", i)))
+				// Repeats don't count.
+				h.Add(hashString(fmt.Sprintf("value-%d", i)))
+			}
+
+			got := float64(h.Estimate())
+			if math.Abs(got-float64(n)) > max(1, hllTolerance*float64(n)) {
+				t.Errorf("got estimate %v, want %d within %v", got, n, hllTolerance)
+			}
+		})
+	}
+}
+
+func TestHyperLogLog_EstimateFloats(t *testing.T) {
+	n := 50_000
+
+	h := &HyperLogLog{}
+	for i := range n {
+		// Nearby values differ only in their low bits.
+		h.Add(hashFloat(1 + float64(i)*1e-12))
+	}
+
+	got := float64(h.Estimate())
+	if math.Abs(got-float64(n)) > hllTolerance*float64(n) {
+		t.Errorf("got estimate %v, want %d within %v", got, n, hllTolerance)
+	}
+}
+
+// TestHyperLogLog_Merge checks that merging sketches of overlapping streams
+// gives exactly the sketch of the combined stream.
+func TestHyperLogLog_Merge(t *testing.T) {
+	whole := &HyperLogLog{}
+	a, b := &HyperLogLog{}, &HyperLogLog{}
+	for i := range 30_000 {
+		hash := hashString(fmt.Sprintf("value-%d", i))
+		whole.Add(hash)
+		if i < 20_000 {
+			a.Add(hash)
+		}
+		if i >= 10_000 {
+			b.Add(hash)
+		}
+	}
+
+	a.Merge(b)
+	if a.Registers != whole.Registers {
+		t.Error("merged registers differ from the registers of the combined stream")
+	}
+	if got, want := a.Estimate(), whole.Estimate(); got != want {
+		t.Errorf("got merged estimate %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,452 @@
+package jsonl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrSchema = errors.New("inferring schema")
+
+// SchemaNode is a key in the tree of fields observed in JSON objects.
+type SchemaNode struct {
+	// Name is the JSON key.
+	Name string
+	// Depth is the number of arrays the values are nested in, so 0 for plain
+	// values and 1 for arrays.
+	Depth int
+
+	// Field holds the values seen, if the node is not an object.
+	Field Field
+	// Children are the keys of the object, sorted by name.
+	Children []*SchemaNode
+}
+
+// NewSchemaTree builds the tree of fields from values collected by path, as
+// the stats command does. Paths are the keys leading to the value, each
+// preceded by "." and followed by "[]" for every array it is nested in, as in
+// ".a.b[].c".
+func NewSchemaTree(fields map[string]Field) (*SchemaNode, error) {
+	root := &SchemaNode{}
+
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if path == "" {
+			// The records themselves.
+			continue
+		}
+		if !strings.HasPrefix(path, ".") {
+			return nil, fmt.Errorf("%w: path %q is not within an object", ErrSchema, path)
+		}
+
+		node := root
+		for _, key := range strings.Split(path[1:], ".") {
+			arrays := 0
+			for strings.HasSuffix(key, "[]") {
+				key = strings.TrimSuffix(key, "[]")
+				arrays++
+			}
+
+			node = node.child(key, arrays)
+		}
+
+		switch field := fields[path].(type) {
+		case *ObjectField:
+			// Objects are described by their children.
+		case *ArrayField:
+			// Arrays are described by their elements, which are at the same
+			// node one level deeper.
+			node.Depth = max(node.Depth, depth(path)+1)
+		default:
+			if node.Field == nil || depth(path) >= node.Depth {
+				node.Field = field
+			}
+		}
+	}
+
+	return root, nil
+}
+
+// depth is the number of arrays the last key in path is nested in.
+func depth(path string) int {
+	trimmed := strings.TrimRight(path, "[]")
+	return (len(path) - len(trimmed)) / 2
+}
+
+// child returns the child with the given name, adding it if there is none. If
+// the key has been seen both in and out of arrays, the deepest nesting wins.
+func (n *SchemaNode) child(name string, depth int) *SchemaNode {
+	i := sort.Search(len(n.Children), func(i int) bool {
+		return n.Children[i].Name >= name
+	})
+	if i < len(n.Children) && n.Children[i].Name == name {
+		c := n.Children[i]
+		c.Depth = max(c.Depth, depth)
+		return c
+	}
+
+	c := &SchemaNode{Name: name, Depth: depth}
+	n.Children = append(n.Children, nil)
+	copy(n.Children[i+1:], n.Children[i:])
+	n.Children[i] = c
+
+	return c
+}
+
+// IsObject is whether the key holds objects. Keys which hold both objects and
+// other values are treated as objects.
+func (n *SchemaNode) IsObject() bool {
+	return len(n.Children) > 0
+}
+
+// Proto renders the tree as a draft proto3 file, with the root as a message
+// named name. Enum-like strings become enums, arrays become repeated fields,
+// and objects become messages.
+func (n *SchemaNode) Proto(name string) string {
+	w := &protoWriter{
+		names: make(map[string]bool),
+	}
+	w.message(n, name, "")
+
+	result := strings.Builder{}
+	result.WriteString("syntax = \"proto3\";\n")
+	for _, block := range append(w.messages, w.enums...) {
+		result.WriteString("\n")
+		result.WriteString(block)
+	}
+
+	return result.String()
+}
+
+type protoWriter struct {
+	// names are the message and enum names used so far.
+	names map[string]bool
+
+	messages []string
+	enums    []string
+}
+
+// typeName returns a unique name for a message or enum, falling back to
+// prefixing the parent's name.
+func (w *protoWriter) typeName(name, parent string) string {
+	if name == "" {
+		name = "Value"
+	}
+	if w.names[name] {
+		name = parent + name
+	}
+	for i := 2; w.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+	}
+	w.names[name] = true
+
+	return name
+}
+
+func (w *protoWriter) message(n *SchemaNode, name, parent string) string {
+	name = w.typeName(name, parent)
+
+	// Reserve a slot so messages appear before the messages they contain.
+	slot := len(w.messages)
+	w.messages = append(w.messages, "")
+
+	result := strings.Builder{}
+	result.WriteString(fmt.Sprintf("message %s {\n", name))
+
+	fieldNames := make(map[string]bool)
+	number := 1
+	for _, c := range n.Children {
+		fieldName := protoFieldName(c.Name)
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = fmt.Sprintf("%s_%d", protoFieldName(c.Name), i)
+		}
+		fieldNames[fieldName] = true
+
+		var comments []string
+		if fieldName != c.Name {
+			comments = append(comments, fmt.Sprintf("json = %q", c.Name))
+		}
+		if c.Depth > 1 {
+			comments = append(comments, fmt.Sprintf("arrays nested %d deep", c.Depth))
+		}
+
+		var fieldType string
+		switch {
+		case c.IsObject():
+			fieldType = w.message(c, upperCamel(c.Name), name)
+		case c.Field == nil:
+			continue
+		default:
+			summary := c.Field.Summary()
+			switch summary.Type {
+			case "null":
+				result.WriteString(fmt.Sprintf("  // %s is always null.\n", fieldName))
+				continue
+			case "enum":
+				fieldType = w.enum(c, summary, name)
+			default:
+				fieldType = protoScalar(summary.Type)
+			}
+		}
+
+		if c.Depth > 0 {
+			fieldType = "repeated " + fieldType
+		}
+
+		line := fmt.Sprintf("  %s %s = %d;", fieldType, fieldName, number)
+		if len(comments) > 0 {
+			line += " // " + strings.Join(comments, ", ")
+		}
+		result.WriteString(line + "\n")
+		number++
+	}
+
+	result.WriteString("}\n")
+	w.messages[slot] = result.String()
+
+	return name
+}
+
+func (w *protoWriter) enum(n *SchemaNode, summary Summary, parent string) string {
+	base := upperCamel(n.Name)
+	if !strings.HasSuffix(base, "Type") {
+		base += "Type"
+	}
+	name := w.typeName(base, parent)
+
+	prefix := upperSnake(strings.TrimSuffix(name, "Type"))
+
+	values := make([]string, 0, len(summary.Values))
+	for value := range summary.Values {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	result := strings.Builder{}
+	result.WriteString(fmt.Sprintf("enum %s {\n", name))
+	result.WriteString(fmt.Sprintf("  %s_UNSPECIFIED = 0;\n", prefix))
+
+	used := map[string]bool{prefix + "_UNSPECIFIED": true}
+	for i, value := range values {
+		valueName := upperSnake(value)
+		if valueName == "" {
+			valueName = "EMPTY"
+		}
+		valueName = prefix + "_" + valueName
+		for j := 2; used[valueName]; j++ {
+			valueName = fmt.Sprintf("%s_%s_%d", prefix, upperSnake(value), j)
+		}
+		used[valueName] = true
+
+		result.WriteString(fmt.Sprintf("  %s = %d; // %q\n", valueName, i+1, value))
+	}
+	result.WriteString("}\n")
+
+	w.enums = append(w.enums, result.String())
+
+	return name
+}
+
+// protoScalar maps the types inferred by NumberField and other fields to
+// proto3 scalar types.
+func protoScalar(fieldType string) string {
+	switch fieldType {
+	case "int8", "int16", "int32":
+		return "int32"
+	case "uint8", "uint16", "uint32":
+		return "uint32"
+	case "int64", "uint64", "bool", "string":
+		return fieldType
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	default:
+		return "string"
+	}
+}
+
+// ArrowSchema returns the Arrow schema matching the tree. Enum-like strings
+// become dictionaries, arrays become lists, and objects become structs.
+func (n *SchemaNode) ArrowSchema() *arrow.Schema {
+	return arrow.NewSchema(n.arrowFields(), nil)
+}
+
+func (n *SchemaNode) arrowFields() []arrow.Field {
+	var fields []arrow.Field
+	for _, c := range n.Children {
+		var dataType arrow.DataType
+		switch {
+		case c.IsObject():
+			dataType = arrow.StructOf(c.arrowFields()...)
+		case c.Field == nil:
+			continue
+		default:
+			dataType = arrowScalar(c.Field.Summary().Type)
+		}
+
+		for range c.Depth {
+			dataType = arrow.ListOf(dataType)
+		}
+
+		fields = append(fields, arrow.Field{Name: c.Name, Type: dataType})
+	}
+
+	return fields
+}
+
+func arrowScalar(fieldType string) arrow.DataType {
+	switch fieldType {
+	case "null":
+		return arrow.Null
+	case "bool":
+		return arrow.FixedWidthTypes.Boolean
+	case "int8":
+		return arrow.PrimitiveTypes.Int8
+	case "int16":
+		return arrow.PrimitiveTypes.Int16
+	case "int32":
+		return arrow.PrimitiveTypes.Int32
+	case "int64":
+		return arrow.PrimitiveTypes.Int64
+	case "uint8":
+		return arrow.PrimitiveTypes.Uint8
+	case "uint16":
+		return arrow.PrimitiveTypes.Uint16
+	case "uint32":
+		return arrow.PrimitiveTypes.Uint32
+	case "uint64":
+		return arrow.PrimitiveTypes.Uint64
+	case "float32":
+		return arrow.PrimitiveTypes.Float32
+	case "float64":
+		return arrow.PrimitiveTypes.Float64
+	case "enum":
+		return &arrow.DictionaryType{
+			IndexType: arrow.PrimitiveTypes.Uint8,
+			ValueType: arrow.BinaryTypes.String,
+			Ordered:   false,
+		}
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// ArrowGo renders schema as a Go variable declaration in the style of
+// pkg/tables.
+func ArrowGo(name string, schema *arrow.Schema) string {
+	result := strings.Builder{}
+	result.WriteString(fmt.Sprintf("var %sSchema = arrow.NewSchema([]arrow.Field{\n", name))
+	for _, field := range schema.Fields() {
+		result.WriteString(fmt.Sprintf("\t{Name: %q, Type: %s},\n", field.Name, arrowGoType(field.Type, 1)))
+	}
+	result.WriteString("}, nil)\n")
+
+	return result.String()
+}
+
+func arrowGoType(dataType arrow.DataType, indent int) string {
+	switch t := dataType.(type) {
+	case *arrow.ListType:
+		return fmt.Sprintf("arrow.ListOf(%s)", arrowGoType(t.Elem(), indent))
+	case *arrow.StructType:
+		tabs := strings.Repeat("\t", indent)
+		result := strings.Builder{}
+		result.WriteString("arrow.StructOf(\n")
+		for _, field := range t.Fields() {
+			result.WriteString(fmt.Sprintf("%s\tarrow.Field{Name: %q, Type: %s},\n", tabs, field.Name, arrowGoType(field.Type, indent+1)))
+		}
+		result.WriteString(tabs + ")")
+		return result.String()
+	case *arrow.DictionaryType:
+		tabs := strings.Repeat("\t", indent)
+		return fmt.Sprintf("&arrow.DictionaryType{\n%[1]s\tIndexType: %[2]s,\n%[1]s\tValueType: %[3]s,\n%[1]s\tOrdered:   false,\n%[1]s}",
+			tabs, arrowGoType(t.IndexType, indent+1), arrowGoType(t.ValueType, indent+1))
+	}
+
+	switch dataType.ID() {
+	case arrow.NULL:
+		return "arrow.Null"
+	case arrow.BOOL:
+		return "arrow.FixedWidthTypes.Boolean"
+	case arrow.STRING:
+		return "arrow.BinaryTypes.String"
+	default:
+		// Remaining types are primitives, named like "int8" and "float64".
+		return "arrow.PrimitiveTypes." + upperCamel(dataType.Name())
+	}
+}
+
+// words splits a JSON key like "offsetStart", "software-name", or "URL" into
+// its words.
+func words(key string) []string {
+	var result []string
+	var word []rune
+
+	runes := []rune(key)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				result = append(result, string(word))
+				word = nil
+			}
+			continue
+		}
+
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := word[len(word)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// Split "offsetStart" before "S", and "URLFor" before "F".
+			if !unicode.IsUpper(prev) || nextIsLower {
+				result = append(result, string(word))
+				word = nil
+			}
+		}
+
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		result = append(result, string(word))
+	}
+
+	return result
+}
+
+// protoFieldName converts a JSON key to a lower_snake_case field name.
+func protoFieldName(key string) string {
+	name := strings.ToLower(strings.Join(words(key), "_"))
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "f_" + name
+	}
+	return name
+}
+
+func upperSnake(key string) string {
+	return strings.ToUpper(strings.Join(words(key), "_"))
+}
+
+func upperCamel(key string) string {
+	result := strings.Builder{}
+	for _, word := range words(key) {
+		// Words may begin with non-ASCII letters, so split off the first rune
+		// rather than the first byte.
+		first, size := utf8.DecodeRuneInString(word)
+		result.WriteRune(unicode.ToUpper(first))
+		result.WriteString(strings.ToLower(word[size:]))
+	}
+
+	name := result.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "M" + name
+	}
+	return name
+}
@@ -0,0 +1,29 @@
+package jsonl
+
+import (
+	"testing"
+)
+
+func TestUpperCamel(t *testing.T) {
+	tcs := []struct {
+		key  string
+		want string
+	}{
+		{key: "offsetStart", want: "OffsetStart"},
+		{key: "software-name", want: "SoftwareName"},
+		{key: "URLFor", want: "UrlFor"},
+		{key: "2d_box", want: "M2dBox"},
+		{key: "éditeur", want: "Éditeur"},
+		{key: "données_source", want: "DonnéesSource"},
+		{key: "über", want: "Über"},
+		{key: "", want: ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.key, func(t *testing.T) {
+			if got := upperCamel(tc.key); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,25 +1,131 @@
 package jsonl
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"io"
 	"iter"
+	"math"
 	"sort"
+	"strings"
 )
 
-const keyField = "file"
+// DefaultKeyPath is the field merge records each entry's source file name in.
+// File names begin with the paper's UUID.
+const DefaultKeyPath = "file"
 
-type uint128 struct {
-	_1 uint64
-	_2 uint64
+type KeyType string
+
+const (
+	// KeyUUID sorts by the UUID at the start of a string field, such as
+	// "01234567-89ab-cdef-0123-456789abcdef.software.json".
+	KeyUUID KeyType = "uuid"
+	// KeyString sorts strings bytewise.
+	KeyString KeyType = "string"
+	// KeyNumber sorts numbers numerically.
+	KeyNumber KeyType = "number"
+)
+
+// DedupePolicy determines what happens to entries which share a key.
+type DedupePolicy string
+
+const (
+	// DedupeNone keeps every entry, with entries sharing a key in input order.
+	DedupeNone DedupePolicy = "none"
+	// DedupeFirst keeps the first entry in input order for each key.
+	DedupeFirst DedupePolicy = "first"
+	// DedupeLast keeps the last entry in input order for each key.
+	DedupeLast DedupePolicy = "last"
+	// DedupeError stops sorting at the first duplicate key.
+	DedupeError DedupePolicy = "error"
+)
+
+var (
+	ErrSort         = errors.New("sorting entries")
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// Sorter sorts JSON objects by the value at a key path.
+//
+// The zero Sorter sorts entirely in memory by the UUID prefixing the "file"
+// field and keeps entries with duplicate keys.
+type Sorter struct {
+	// KeyPath is the dotted path of the field to sort by, such as
+	// "metadata.id". Defaults to DefaultKeyPath.
+	KeyPath string
+	// KeyType is how to interpret the field. Defaults to KeyUUID.
+	KeyType KeyType
+	// Dedupe is what to do with entries sharing a key. Defaults to DedupeNone.
+	Dedupe DedupePolicy
+
+	// MaxMemory, if positive, is the approximate number of bytes of entries
+	// to hold in memory before spilling sorted runs to temporary files in
+	// TempDir. Otherwise the entire input is sorted in memory.
+	MaxMemory int64
+	TempDir   string
+
+	collisions int
+	err        error
 }
 
+// Sort sorts entries by the UUID prefixing their "file" field. Entries with
+// the same UUID are kept in input order.
 func Sort(seq iter.Seq2[*map[string]any, error]) (iter.Seq[map[string]any], error) {
-	var keys []uint128
-	valMap := make(map[uint128]map[string]any)
+	return (&Sorter{}).Sort(seq)
+}
+
+// Collisions is the number of entries which had the same key as an earlier
+// entry. Only complete once the sorted sequence has been consumed.
+func (s *Sorter) Collisions() int {
+	return s.collisions
+}
+
+// Err returns the first error encountered while iterating over the sorted
+// sequence, which ends the sequence early. Includes ErrDuplicateKey for
+// DedupeError.
+func (s *Sorter) Err() error {
+	return s.err
+}
+
+// Sort reads every entry in seq and returns them in key order.
+//
+// Errors reading seq or extracting keys are returned immediately. Errors
+// found while producing the sorted sequence end it and are reported by Err.
+func (s *Sorter) Sort(seq iter.Seq2[*map[string]any, error]) (iter.Seq[map[string]any], error) {
+	keyPath := s.KeyPath
+	if keyPath == "" {
+		keyPath = DefaultKeyPath
+	}
+	path := strings.Split(keyPath, ".")
+
+	keyType := s.KeyType
+	if keyType == "" {
+		keyType = KeyUUID
+	}
+
+	var sorted iter.Seq2[[]byte, map[string]any]
+	var err error
+	if s.MaxMemory > 0 {
+		sorted, err = s.externalSort(seq, path, keyType)
+	} else {
+		sorted, err = s.memorySort(seq, path, keyType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dedupe(sorted, keyType), nil
+}
+
+func (s *Sorter) memorySort(seq iter.Seq2[*map[string]any, error], path []string, keyType KeyType) (iter.Seq2[[]byte, map[string]any], error) {
+	type keyed struct {
+		key   []byte
+		value map[string]any
+	}
+	var entries []keyed
 
 	for v, err := range seq {
 		if err != nil {
@@ -29,45 +135,162 @@ func Sort(seq iter.Seq2[*map[string]any, error]) (iter.Seq[map[string]any], erro
 			return nil, err
 		}
 
-		keyAny, exists := (*v)[keyField]
+		key, err := entryKey(*v, path, keyType)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, keyed{key: key, value: *v})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	return func(yield func([]byte, map[string]any) bool) {
+		for _, entry := range entries {
+			if !yield(entry.key, entry.value) {
+				return
+			}
+		}
+	}, nil
+}
+
+// dedupe applies the Sorter's DedupePolicy to a sequence of entries in key
+// order, counting collisions as it goes.
+func (s *Sorter) dedupe(sorted iter.Seq2[[]byte, map[string]any], keyType KeyType) iter.Seq[map[string]any] {
+	return func(yield func(map[string]any) bool) {
+		var prevKey []byte
+		var held map[string]any
+
+		for key, v := range sorted {
+			isDuplicate := prevKey != nil && bytes.Equal(key, prevKey)
+			prevKey = key
+			if isDuplicate {
+				s.collisions++
+			}
+
+			switch s.Dedupe {
+			case DedupeFirst:
+				if isDuplicate {
+					continue
+				}
+			case DedupeLast:
+				// Hold each entry until we see the next key, so only the last
+				// of each group is yielded.
+				if held != nil && !isDuplicate {
+					if !yield(held) {
+						return
+					}
+				}
+				held = v
+				continue
+			case DedupeError:
+				if isDuplicate {
+					s.err = fmt.Errorf("%w: %w %q", ErrSort, ErrDuplicateKey, FormatKey(key, keyType))
+					return
+				}
+			}
+
+			if !yield(v) {
+				return
+			}
+		}
+
+		if held != nil {
+			yield(held)
+		}
+	}
+}
+
+// Key returns the bytes to sort the entry by, reading the field at the dotted
+// keyPath. Keys of the same type compare with bytes.Compare in the same order
+// as their values.
+func Key(v map[string]any, keyPath string, keyType KeyType) ([]byte, error) {
+	return entryKey(v, strings.Split(keyPath, "."), keyType)
+}
+
+// entryKey is Key with the path already split.
+func entryKey(v map[string]any, path []string, keyType KeyType) ([]byte, error) {
+	var keyAny any = v
+	for _, field := range path {
+		obj, isObject := keyAny.(map[string]any)
+		if !isObject {
+			return nil, fmt.Errorf("%w: entry key path %q passes through %T, not an object", ErrSort, strings.Join(path, "."), keyAny)
+		}
+
+		var exists bool
+		keyAny, exists = obj[field]
 		if !exists {
-			return nil, fmt.Errorf("entry missing key field %q", keyField)
+			return nil, fmt.Errorf("%w: entry missing key field %q", ErrSort, strings.Join(path, "."))
 		}
+	}
 
+	switch keyType {
+	case KeyUUID:
 		keyString, isString := keyAny.(string)
 		if !isString {
-			return nil, fmt.Errorf("entry key field %q is %T, not a string", keyAny, keyField)
+			return nil, fmt.Errorf("%w: entry key field %q is %T, not a string", ErrSort, strings.Join(path, "."), keyAny)
+		}
+		if len(keyString) < 36 {
+			return nil, fmt.Errorf("%w: entry key field %q is too short to be a UUID", ErrSort, keyString)
 		}
 
 		id, err := uuid.Parse(keyString[:36])
 		if err != nil {
-			return nil, fmt.Errorf("entry key field %q is not a UUID: %w", keyString, err)
+			return nil, fmt.Errorf("%w: entry key field %q is not a UUID: %w", ErrSort, keyString, err)
 		}
-		idBytes, err := id.MarshalBinary()
-		if err != nil {
-			return nil, fmt.Errorf("unable to format bytes for entry field %q: %w", keyString, err)
+		return id[:], nil
+	case KeyString:
+		keyString, isString := keyAny.(string)
+		if !isString {
+			return nil, fmt.Errorf("%w: entry key field %q is %T, not a string", ErrSort, strings.Join(path, "."), keyAny)
 		}
+		return []byte(keyString), nil
+	case KeyNumber:
+		keyNumber, isNumber := keyAny.(float64)
+		if !isNumber {
+			return nil, fmt.Errorf("%w: entry key field %q is %T, not a number", ErrSort, strings.Join(path, "."), keyAny)
+		}
+		return numberKey(keyNumber), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown key type %q", ErrSort, keyType)
+	}
+}
 
-		left := binary.BigEndian.Uint64(idBytes[:8])
-		right := binary.BigEndian.Uint64(idBytes[8:])
-		key := uint128{_1: left, _2: right}
-
-		keys = append(keys, key)
-		valMap[key] = *v
+// numberKey encodes f so that byte order matches numeric order: flip the sign
+// bit of non-negative numbers, and every bit of negative numbers.
+func numberKey(f float64) []byte {
+	bits := math.Float64bits(f)
+	if bits&(1<<63) == 0 {
+		bits ^= 1 << 63
+	} else {
+		bits = ^bits
 	}
 
-	sort.Slice(keys, func(i, j int) bool {
-		if keys[i]._1 != keys[j]._1 {
-			return keys[i]._1 < keys[j]._1
-		}
-		return keys[i]._2 < keys[j]._2
-	})
+	return binary.BigEndian.AppendUint64(nil, bits)
+}
 
-	return func(yield func(map[string]any) bool) {
-		for _, key := range keys {
-			if !yield(valMap[key]) {
-				return
+// FormatKey renders a key produced by Key as the value it was made from.
+func FormatKey(key []byte, keyType KeyType) string {
+	switch keyType {
+	case KeyUUID:
+		id, err := uuid.FromBytes(key)
+		if err == nil {
+			return id.String()
+		}
+	case KeyString:
+		return string(key)
+	case KeyNumber:
+		if len(key) == 8 {
+			bits := binary.BigEndian.Uint64(key)
+			if bits&(1<<63) != 0 {
+				bits ^= 1 << 63
+			} else {
+				bits = ^bits
 			}
+			return fmt.Sprint(math.Float64frombits(bits))
 		}
-	}, nil
+	}
+	return fmt.Sprintf("%x", key)
 }
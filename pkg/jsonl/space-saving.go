@@ -0,0 +1,119 @@
+package jsonl
+
+import (
+	"cmp"
+	"sort"
+)
+
+// TopCapacity is the number of candidate heavy hitters to track per field.
+// Counts of the top few values are much more accurate than the last.
+const TopCapacity = 64
+
+// SpaceSaving tracks the most frequent values added to it, using the
+// Space-Saving algorithm. Counts are overestimates by at most Error.
+type SpaceSaving[K cmp.Ordered] struct {
+	Capacity int
+	Counts   map[K]*TopCount
+}
+
+type TopCount struct {
+	Count int
+	// Error is how much Count may exceed the true count.
+	Error int
+}
+
+func NewSpaceSaving[K cmp.Ordered](capacity int) *SpaceSaving[K] {
+	return &SpaceSaving[K]{
+		Capacity: capacity,
+		Counts:   make(map[K]*TopCount, capacity),
+	}
+}
+
+func (s *SpaceSaving[K]) Add(k K) {
+	if c, exists := s.Counts[k]; exists {
+		c.Count++
+		return
+	}
+
+	if len(s.Counts) < s.Capacity {
+		s.Counts[k] = &TopCount{Count: 1}
+		return
+	}
+
+	// Replace the least frequent value, assuming the new value may have been
+	// seen as often as it.
+	minKey, minCount := s.min()
+	delete(s.Counts, minKey)
+	s.Counts[k] = &TopCount{Count: minCount.Count + 1, Error: minCount.Count}
+}
+
+// min returns the least frequent value, breaking ties by the largest value so
+// results don't depend on map order.
+func (s *SpaceSaving[K]) min() (K, *TopCount) {
+	var minKey K
+	var minCount *TopCount
+	for k, c := range s.Counts {
+		if minCount == nil || c.Count < minCount.Count || (c.Count == minCount.Count && k > minKey) {
+			minKey, minCount = k, c
+		}
+	}
+	return minKey, minCount
+}
+
+// floor is the most a value not being tracked may have been seen.
+func (s *SpaceSaving[K]) floor() int {
+	if len(s.Counts) < s.Capacity {
+		return 0
+	}
+	_, minCount := s.min()
+	return minCount.Count
+}
+
+// Merge combines the counts of other into s, as in "Mergeable Summaries"
+// (Agarwal et al.). Values missing from one summary are assumed to have been
+// seen as often as its least frequent value.
+func (s *SpaceSaving[K]) Merge(other *SpaceSaving[K]) {
+	floor, otherFloor := s.floor(), other.floor()
+
+	merged := make(map[K]*TopCount, len(s.Counts)+len(other.Counts))
+	for k, c := range s.Counts {
+		merged[k] = &TopCount{Count: c.Count + otherFloor, Error: c.Error + otherFloor}
+	}
+	for k, c := range other.Counts {
+		if m, exists := merged[k]; exists {
+			m.Count += c.Count - otherFloor
+			m.Error += c.Error - otherFloor
+		} else {
+			merged[k] = &TopCount{Count: c.Count + floor, Error: c.Error + floor}
+		}
+	}
+
+	s.Counts = merged
+	for _, k := range s.sorted()[min(s.Capacity, len(s.Counts)):] {
+		delete(s.Counts, k)
+	}
+}
+
+// sorted returns the tracked values from most to least frequent.
+func (s *SpaceSaving[K]) sorted() []K {
+	keys := make([]K, 0, len(s.Counts))
+	for k := range s.Counts {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := s.Counts[keys[i]], s.Counts[keys[j]]
+		if ci.Count != cj.Count {
+			return ci.Count > cj.Count
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+// Top returns up to n of the most frequent values, most frequent first.
+func (s *SpaceSaving[K]) Top(n int) []K {
+	keys := s.sorted()
+	return keys[:min(n, len(keys))]
+}
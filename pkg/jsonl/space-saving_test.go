@@ -0,0 +1,132 @@
+package jsonl
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// zipfStream is a skewed stream of n values, like the names of software.
+func zipfStream(n int) []uint64 {
+	rng := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(rng, 1.2, 1, 100_000)
+
+	stream := make([]uint64, n)
+	for i := range stream {
+		stream[i] = zipf.Uint64()
+	}
+	return stream
+}
+
+// trueTop is the n most frequent values of counts, most frequent first.
+func trueTop(counts map[uint64]int, n int) []uint64 {
+	var values []uint64
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	return values[:min(n, len(values))]
+}
+
+// checkTop checks s found every one of the true top TopN values, and that
+// its counts bound their true counts.
+func checkTop(t *testing.T, s *SpaceSaving[float64], counts map[uint64]int) {
+	t.Helper()
+
+	found := make(map[uint64]bool)
+	for _, v := range s.Top(TopN) {
+		found[uint64(v)] = true
+	}
+	for _, v := range trueTop(counts, TopN) {
+		if !found[v] {
+			t.Errorf("missed %v, seen %d times, in the top %d", v, counts[v], TopN)
+		}
+	}
+
+	for v, c := range s.Counts {
+		trueCount := counts[uint64(v)]
+		if c.Count < trueCount || c.Count-c.Error > trueCount {
+			t.Errorf("got count %d with error %d for %v, but it was seen %d times", c.Count, c.Error, v, trueCount)
+		}
+	}
+}
+
+func TestSpaceSaving_Top(t *testing.T) {
+	stream := zipfStream(200_000)
+
+	s := NewSpaceSaving[float64](TopCapacity)
+	counts := make(map[uint64]int)
+	for _, v := range stream {
+		s.Add(float64(v))
+		counts[v]++
+	}
+
+	if len(s.Counts) != TopCapacity {
+		t.Errorf("got %d tracked values, want %d", len(s.Counts), TopCapacity)
+	}
+	checkTop(t, s, counts)
+}
+
+func TestSpaceSaving_Merge(t *testing.T) {
+	stream := zipfStream(200_000)
+
+	whole := NewSpaceSaving[float64](TopCapacity)
+	counts := make(map[uint64]int)
+	for _, v := range stream {
+		whole.Add(float64(v))
+		counts[v]++
+	}
+
+	// Merge uneven parts, including one smaller than the capacity.
+	bounds := []int{0, 50, 30_000, 120_000, 200_000}
+	merged := NewSpaceSaving[float64](TopCapacity)
+	for i := 1; i < len(bounds); i++ {
+		part := NewSpaceSaving[float64](TopCapacity)
+		for _, v := range stream[bounds[i-1]:bounds[i]] {
+			part.Add(float64(v))
+		}
+		merged.Merge(part)
+	}
+
+	if len(merged.Counts) > TopCapacity {
+		t.Errorf("got %d tracked values after merging, want at most %d", len(merged.Counts), TopCapacity)
+	}
+	checkTop(t, merged, counts)
+
+	// The most frequent values are far enough apart to be ranked the same.
+	wholeTop, mergedTop := whole.Top(3), merged.Top(3)
+	for i := range wholeTop {
+		if wholeTop[i] != mergedTop[i] {
+			t.Errorf("got merged top 3 %v, want %v as from one pass", mergedTop, wholeTop)
+			break
+		}
+	}
+}
+
+func TestSpaceSaving_MergeExact(t *testing.T) {
+	// Below capacity counts are exact, so merging must be too.
+	a, b := NewSpaceSaving[string](TopCapacity), NewSpaceSaving[string](TopCapacity)
+	for _, v := range []string{"R", "R", "Python", "limma"} {
+		a.Add(v)
+	}
+	for _, v := range []string{"R", "Python", "ImageJ"} {
+		b.Add(v)
+	}
+	a.Merge(b)
+
+	want := map[string]int{"R": 3, "Python": 2, "limma": 1, "ImageJ": 1}
+	for v, count := range want {
+		c := a.Counts[v]
+		if c == nil || c.Count != count || c.Error != 0 {
+			t.Errorf("got %+v for %q, want count %d with no error", c, v, count)
+		}
+	}
+	if len(a.Counts) != len(want) {
+		t.Errorf("got %d values, want %d", len(a.Counts), len(want))
+	}
+}
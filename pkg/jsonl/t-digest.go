@@ -0,0 +1,138 @@
+package jsonl
+
+import (
+	"math"
+	"sort"
+)
+
+// DigestCompression bounds the number of centroids in a TDigest to a small
+// multiple of itself. Larger values are more accurate.
+const DigestCompression = 100
+
+// TDigest estimates quantiles of the values added to it in bounded space,
+// using the merging t-digest of Dunning and Ertl. Estimates are most accurate
+// near the extremes.
+type TDigest struct {
+	Compression float64
+
+	// Centroids are sorted by mean.
+	Centroids []Centroid
+	// Buffer holds values and centroids not yet merged into Centroids.
+	Buffer []Centroid
+
+	Count    float64
+	Min, Max float64
+}
+
+type Centroid struct {
+	Mean   float64
+	Weight float64
+}
+
+func NewTDigest(compression float64) *TDigest {
+	return &TDigest{
+		Compression: compression,
+		Min:         math.Inf(1),
+		Max:         math.Inf(-1),
+	}
+}
+
+func (t *TDigest) Add(x float64) {
+	t.add(Centroid{Mean: x, Weight: 1}, x, x)
+}
+
+func (t *TDigest) add(c Centroid, minValue, maxValue float64) {
+	t.Buffer = append(t.Buffer, c)
+	t.Count += c.Weight
+	t.Min = min(t.Min, minValue)
+	t.Max = max(t.Max, maxValue)
+
+	if len(t.Buffer) >= int(5*t.Compression) {
+		t.compress()
+	}
+}
+
+// Merge adds the values summarized by other to t.
+func (t *TDigest) Merge(other *TDigest) {
+	other.compress()
+	for _, c := range other.Centroids {
+		t.add(c, other.Min, other.Max)
+	}
+}
+
+// compress merges the buffer into the centroids. Neighboring centroids are
+// combined while their weight is small relative to how close they are to
+// either end of the distribution.
+func (t *TDigest) compress() {
+	if len(t.Buffer) == 0 {
+		return
+	}
+
+	all := append(t.Centroids, t.Buffer...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Mean < all[j].Mean
+	})
+
+	merged := make([]Centroid, 0, len(t.Centroids)+1)
+	cur := all[0]
+	weightSoFar := 0.0
+	for _, c := range all[1:] {
+		proposed := cur.Weight + c.Weight
+		q := (weightSoFar + proposed/2) / t.Count
+		limit := 4 * t.Count * q * (1 - q) / t.Compression
+
+		if proposed <= limit {
+			cur.Mean += (c.Mean - cur.Mean) * c.Weight / proposed
+			cur.Weight = proposed
+		} else {
+			merged = append(merged, cur)
+			weightSoFar += cur.Weight
+			cur = c
+		}
+	}
+	merged = append(merged, cur)
+
+	t.Centroids = merged
+	t.Buffer = nil
+}
+
+// Quantile estimates the value below which a fraction q of values fall,
+// interpolating between the centers of neighboring centroids. Returns NaN if
+// no values have been added.
+func (t *TDigest) Quantile(q float64) float64 {
+	t.compress()
+
+	if t.Count == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return t.Min
+	}
+	if q >= 1 {
+		return t.Max
+	}
+
+	target := q * t.Count
+
+	// The center of each centroid is halfway through its weight.
+	prevCenter, prevMean := 0.0, t.Min
+	cumulative := 0.0
+	for _, c := range t.Centroids {
+		center := cumulative + c.Weight/2
+		if target < center {
+			return interpolate(target, prevCenter, center, prevMean, c.Mean)
+		}
+
+		prevCenter, prevMean = center, c.Mean
+		cumulative += c.Weight
+	}
+
+	return interpolate(target, prevCenter, t.Count, prevMean, t.Max)
+}
+
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 <= x0 {
+		return y1
+	}
+	return y0 + (y1-y0)*(x-x0)/(x1-x0)
+}
@@ -0,0 +1,97 @@
+package jsonl
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkQuantiles checks the quantiles of t, a digest of a permutation of 0 to
+// n-1, are close to the true quantiles, and closer near the extremes.
+func checkQuantiles(t *testing.T, digest *TDigest, n int) {
+	t.Helper()
+
+	if digest.Count != float64(n) || digest.Min != 0 || digest.Max != float64(n-1) {
+		t.Errorf("got count %v, min %v, and max %v, want %d, 0, and %d", digest.Count, digest.Min, digest.Max, n, n-1)
+	}
+
+	for _, percentile := range Percentiles {
+		q := float64(percentile) / 100
+		got := digest.Quantile(q) / float64(n)
+
+		// Allowed error in rank, smaller in the tails.
+		tolerance := 0.005
+		if q <= 0.05 || q >= 0.95 {
+			tolerance = 0.001
+		}
+
+		if math.Abs(got-q) > tolerance {
+			t.Errorf("got quantile %v at rank %.4f, want within %v of %v", digest.Quantile(q), got, tolerance, q)
+		}
+	}
+
+	if got := digest.Quantile(0); got != 0 {
+		t.Errorf("got quantile 0 of %v, want the minimum 0", got)
+	}
+	if got := digest.Quantile(1); got != float64(n-1) {
+		t.Errorf("got quantile 1 of %v, want the maximum %d", got, n-1)
+	}
+}
+
+func TestTDigest_Quantile(t *testing.T) {
+	n := 100_000
+	rng := rand.New(rand.NewSource(1))
+
+	digest := NewTDigest(DigestCompression)
+	for _, v := range rng.Perm(n) {
+		digest.Add(float64(v))
+	}
+
+	checkQuantiles(t, digest, n)
+
+	if len(digest.Centroids) > 10*DigestCompression {
+		t.Errorf("got %d centroids, want at most %d", len(digest.Centroids), 10*DigestCompression)
+	}
+}
+
+func TestTDigest_Merge(t *testing.T) {
+	n := 100_000
+	rng := rand.New(rand.NewSource(1))
+	values := rng.Perm(n)
+
+	// Sorted parts are the hardest case, since each covers a narrow range.
+	merged := NewTDigest(DigestCompression)
+	for start := 0; start < n; start += 7_000 {
+		part := NewTDigest(DigestCompression)
+		for v := start; v < min(start+7_000, n); v++ {
+			part.Add(float64(v))
+		}
+		merged.Merge(part)
+	}
+	checkQuantiles(t, merged, n)
+
+	// Parts of a shuffled stream.
+	merged = NewTDigest(DigestCompression)
+	for start := 0; start < n; start += 9_000 {
+		part := NewTDigest(DigestCompression)
+		for _, v := range values[start:min(start+9_000, n)] {
+			part.Add(float64(v))
+		}
+		merged.Merge(part)
+	}
+	checkQuantiles(t, merged, n)
+}
+
+func TestTDigest_Empty(t *testing.T) {
+	digest := NewTDigest(DigestCompression)
+	if got := digest.Quantile(0.5); !math.IsNaN(got) {
+		t.Errorf("got median %v of no values, want NaN", got)
+	}
+
+	// Merging an empty digest changes nothing.
+	digest.Add(3)
+	digest.Merge(NewTDigest(DigestCompression))
+	if got := digest.Quantile(0.5); got != 3 {
+		t.Errorf("got median %v of one value, want 3", got)
+	}
+}
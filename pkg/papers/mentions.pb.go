@@ -80,7 +80,20 @@ type Mention struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SoftwareName *SoftwareName `protobuf:"bytes,1,opt,name=software_name,json=softwareName,proto3" json:"software_name,omitempty"`
+	SoftwareName *SoftwareName `protobuf:"bytes,1,opt,name=software_name,json=softwareName,proto3" json:"software_name,omitempty"` // json = "software-name"
+	// type is the kind of entity mentioned, generally "software".
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // json = "type"
+	// software_type distinguishes standalone software from environments,
+	// components, and implicit mentions.
+	SoftwareType string        `protobuf:"bytes,3,opt,name=software_type,json=softwareType,proto3" json:"software_type,omitempty"` // json = "software-type"
+	Version      *Version      `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`                               // json = "version"
+	Language     *SoftwareName `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"`                             // json = "language"
+	Url          *SoftwareName `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`                                       // json = "url"
+	// context is the passage of text the mention was extracted from.
+	Context                   string             `protobuf:"bytes,7,opt,name=context,proto3" json:"context,omitempty"`                                                                        // json = "context"
+	MentionContextAttributes  *ContextAttributes `protobuf:"bytes,8,opt,name=mention_context_attributes,json=mentionContextAttributes,proto3" json:"mention_context_attributes,omitempty"`    // json = "mentionContextAttributes"
+	DocumentContextAttributes *ContextAttributes `protobuf:"bytes,9,opt,name=document_context_attributes,json=documentContextAttributes,proto3" json:"document_context_attributes,omitempty"` // json = "documentContextAttributes"
+	References                []*Reference       `protobuf:"bytes,10,rep,name=references,proto3" json:"references,omitempty"`                                                                 // json = "references"
 }
 
 func (x *Mention) Reset() {
@@ -122,13 +135,84 @@ func (x *Mention) GetSoftwareName() *SoftwareName {
 	return nil
 }
 
+func (x *Mention) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Mention) GetSoftwareType() string {
+	if x != nil {
+		return x.SoftwareType
+	}
+	return ""
+}
+
+func (x *Mention) GetVersion() *Version {
+	if x != nil {
+		return x.Version
+	}
+	return nil
+}
+
+func (x *Mention) GetLanguage() *SoftwareName {
+	if x != nil {
+		return x.Language
+	}
+	return nil
+}
+
+func (x *Mention) GetUrl() *SoftwareName {
+	if x != nil {
+		return x.Url
+	}
+	return nil
+}
+
+func (x *Mention) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *Mention) GetMentionContextAttributes() *ContextAttributes {
+	if x != nil {
+		return x.MentionContextAttributes
+	}
+	return nil
+}
+
+func (x *Mention) GetDocumentContextAttributes() *ContextAttributes {
+	if x != nil {
+		return x.DocumentContextAttributes
+	}
+	return nil
+}
+
+func (x *Mention) GetReferences() []*Reference {
+	if x != nil {
+		return x.References
+	}
+	return nil
+}
+
+// SoftwareName is a span of text naming a piece of software, or its language
+// or url.
 type SoftwareName struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	NormalizedForm string `protobuf:"bytes,1,opt,name=normalized_form,json=normalizedForm,proto3" json:"normalized_form,omitempty"`
-	WikidataId     string `protobuf:"bytes,2,opt,name=wikidata_id,json=wikidataId,proto3" json:"wikidata_id,omitempty"`
+	NormalizedForm string `protobuf:"bytes,1,opt,name=normalized_form,json=normalizedForm,proto3" json:"normalized_form,omitempty"` // json = "normalizedForm"
+	WikidataId     string `protobuf:"bytes,2,opt,name=wikidata_id,json=wikidataId,proto3" json:"wikidata_id,omitempty"`             // json = "wikidataId"
+	RawForm        string `protobuf:"bytes,3,opt,name=raw_form,json=rawForm,proto3" json:"raw_form,omitempty"`                      // json = "rawForm"
+	// offset_start and offset_end are the character offsets of raw_form in
+	// the mention's context.
+	OffsetStart   int32          `protobuf:"varint,4,opt,name=offset_start,json=offsetStart,proto3" json:"offset_start,omitempty"`      // json = "offsetStart"
+	OffsetEnd     int32          `protobuf:"varint,5,opt,name=offset_end,json=offsetEnd,proto3" json:"offset_end,omitempty"`            // json = "offsetEnd"
+	BoundingBoxes []*BoundingBox `protobuf:"bytes,6,rep,name=bounding_boxes,json=boundingBoxes,proto3" json:"bounding_boxes,omitempty"` // json = "boundingBoxes"
 }
 
 func (x *SoftwareName) Reset() {
@@ -177,6 +261,369 @@ func (x *SoftwareName) GetWikidataId() string {
 	return ""
 }
 
+func (x *SoftwareName) GetRawForm() string {
+	if x != nil {
+		return x.RawForm
+	}
+	return ""
+}
+
+func (x *SoftwareName) GetOffsetStart() int32 {
+	if x != nil {
+		return x.OffsetStart
+	}
+	return 0
+}
+
+func (x *SoftwareName) GetOffsetEnd() int32 {
+	if x != nil {
+		return x.OffsetEnd
+	}
+	return 0
+}
+
+func (x *SoftwareName) GetBoundingBoxes() []*BoundingBox {
+	if x != nil {
+		return x.BoundingBoxes
+	}
+	return nil
+}
+
+type Version struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RawForm        string         `protobuf:"bytes,1,opt,name=raw_form,json=rawForm,proto3" json:"raw_form,omitempty"`                      // json = "rawForm"
+	NormalizedForm string         `protobuf:"bytes,2,opt,name=normalized_form,json=normalizedForm,proto3" json:"normalized_form,omitempty"` // json = "normalizedForm"
+	OffsetStart    int32          `protobuf:"varint,3,opt,name=offset_start,json=offsetStart,proto3" json:"offset_start,omitempty"`         // json = "offsetStart"
+	OffsetEnd      int32          `protobuf:"varint,4,opt,name=offset_end,json=offsetEnd,proto3" json:"offset_end,omitempty"`               // json = "offsetEnd"
+	BoundingBoxes  []*BoundingBox `protobuf:"bytes,5,rep,name=bounding_boxes,json=boundingBoxes,proto3" json:"bounding_boxes,omitempty"`    // json = "boundingBoxes"
+}
+
+func (x *Version) Reset() {
+	*x = Version{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_papers_mentions_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Version) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Version) ProtoMessage() {}
+
+func (x *Version) ProtoReflect() protoreflect.Message {
+	mi := &file_papers_mentions_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Version.ProtoReflect.Descriptor instead.
+func (*Version) Descriptor() ([]byte, []int) {
+	return file_papers_mentions_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Version) GetRawForm() string {
+	if x != nil {
+		return x.RawForm
+	}
+	return ""
+}
+
+func (x *Version) GetNormalizedForm() string {
+	if x != nil {
+		return x.NormalizedForm
+	}
+	return ""
+}
+
+func (x *Version) GetOffsetStart() int32 {
+	if x != nil {
+		return x.OffsetStart
+	}
+	return 0
+}
+
+func (x *Version) GetOffsetEnd() int32 {
+	if x != nil {
+		return x.OffsetEnd
+	}
+	return 0
+}
+
+func (x *Version) GetBoundingBoxes() []*BoundingBox {
+	if x != nil {
+		return x.BoundingBoxes
+	}
+	return nil
+}
+
+// BoundingBox is the location of text on a page of the source PDF.
+type BoundingBox struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// p is the page number.
+	P int32   `protobuf:"varint,1,opt,name=p,proto3" json:"p,omitempty"`
+	X float32 `protobuf:"fixed32,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y float32 `protobuf:"fixed32,3,opt,name=y,proto3" json:"y,omitempty"`
+	W float32 `protobuf:"fixed32,4,opt,name=w,proto3" json:"w,omitempty"`
+	H float32 `protobuf:"fixed32,5,opt,name=h,proto3" json:"h,omitempty"`
+}
+
+func (x *BoundingBox) Reset() {
+	*x = BoundingBox{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_papers_mentions_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BoundingBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoundingBox) ProtoMessage() {}
+
+func (x *BoundingBox) ProtoReflect() protoreflect.Message {
+	mi := &file_papers_mentions_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoundingBox.ProtoReflect.Descriptor instead.
+func (*BoundingBox) Descriptor() ([]byte, []int) {
+	return file_papers_mentions_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BoundingBox) GetP() int32 {
+	if x != nil {
+		return x.P
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetX() float32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetY() float32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetW() float32 {
+	if x != nil {
+		return x.W
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetH() float32 {
+	if x != nil {
+		return x.H
+	}
+	return 0
+}
+
+// ContextAttributes are the classifier's judgements of how the software is
+// discussed, either in the context of one mention or across the document.
+type ContextAttributes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Used    *Attribute `protobuf:"bytes,1,opt,name=used,proto3" json:"used,omitempty"`
+	Created *Attribute `protobuf:"bytes,2,opt,name=created,proto3" json:"created,omitempty"`
+	Shared  *Attribute `protobuf:"bytes,3,opt,name=shared,proto3" json:"shared,omitempty"`
+}
+
+func (x *ContextAttributes) Reset() {
+	*x = ContextAttributes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_papers_mentions_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContextAttributes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextAttributes) ProtoMessage() {}
+
+func (x *ContextAttributes) ProtoReflect() protoreflect.Message {
+	mi := &file_papers_mentions_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextAttributes.ProtoReflect.Descriptor instead.
+func (*ContextAttributes) Descriptor() ([]byte, []int) {
+	return file_papers_mentions_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ContextAttributes) GetUsed() *Attribute {
+	if x != nil {
+		return x.Used
+	}
+	return nil
+}
+
+func (x *ContextAttributes) GetCreated() *Attribute {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *ContextAttributes) GetShared() *Attribute {
+	if x != nil {
+		return x.Shared
+	}
+	return nil
+}
+
+type Attribute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value bool    `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	Score float32 `protobuf:"fixed32,2,opt,name=score,proto3" json:"score,omitempty"`
+}
+
+func (x *Attribute) Reset() {
+	*x = Attribute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_papers_mentions_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Attribute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attribute) ProtoMessage() {}
+
+func (x *Attribute) ProtoReflect() protoreflect.Message {
+	mi := &file_papers_mentions_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attribute.ProtoReflect.Descriptor instead.
+func (*Attribute) Descriptor() ([]byte, []int) {
+	return file_papers_mentions_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Attribute) GetValue() bool {
+	if x != nil {
+		return x.Value
+	}
+	return false
+}
+
+func (x *Attribute) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Reference struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RefKey int32  `protobuf:"varint,1,opt,name=ref_key,json=refKey,proto3" json:"ref_key,omitempty"` // json = "refKey"
+	Tei    string `protobuf:"bytes,2,opt,name=tei,proto3" json:"tei,omitempty"`                      // json = "tei"
+}
+
+func (x *Reference) Reset() {
+	*x = Reference{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_papers_mentions_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Reference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reference) ProtoMessage() {}
+
+func (x *Reference) ProtoReflect() protoreflect.Message {
+	mi := &file_papers_mentions_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reference.ProtoReflect.Descriptor instead.
+func (*Reference) Descriptor() ([]byte, []int) {
+	return file_papers_mentions_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Reference) GetRefKey() int32 {
+	if x != nil {
+		return x.RefKey
+	}
+	return 0
+}
+
+func (x *Reference) GetTei() string {
+	if x != nil {
+		return x.Tei
+	}
+	return ""
+}
+
 var File_papers_mentions_proto protoreflect.FileDescriptor
 
 var file_papers_mentions_proto_rawDesc = []byte{
@@ -187,17 +634,85 @@ var file_papers_mentions_proto_rawDesc = []byte{
 	0x32, 0x05, 0x2e, 0x55, 0x55, 0x49, 0x44, 0x52, 0x02, 0x69, 0x64, 0x12, 0x24, 0x0a, 0x08, 0x6d,
 	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e,
 	0x4d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x6d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x22, 0x3d, 0x0a, 0x07, 0x4d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x0d,
-	0x73, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x4e, 0x61,
-	0x6d, 0x65, 0x52, 0x0c, 0x73, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x4e, 0x61, 0x6d, 0x65,
-	0x22, 0x58, 0x0a, 0x0c, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x4e, 0x61, 0x6d, 0x65,
-	0x12, 0x27, 0x0a, 0x0f, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x66,
-	0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6e, 0x6f, 0x72, 0x6d, 0x61,
-	0x6c, 0x69, 0x7a, 0x65, 0x64, 0x46, 0x6f, 0x72, 0x6d, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x69, 0x6b,
-	0x69, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
-	0x77, 0x69, 0x6b, 0x69, 0x64, 0x61, 0x74, 0x61, 0x49, 0x64, 0x42, 0x0c, 0x5a, 0x0a, 0x70, 0x6b,
-	0x67, 0x2f, 0x70, 0x61, 0x70, 0x65, 0x72, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x22, 0xd2, 0x03, 0x0a, 0x07, 0x4d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a,
+	0x0d, 0x73, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x52, 0x0c, 0x73, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72,
+	0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x6f,
+	0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x29,
+	0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0d, 0x2e, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x52,
+	0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x03, 0x75, 0x72, 0x6c,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x53, 0x6f, 0x66, 0x74, 0x77, 0x61, 0x72,
+	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x78, 0x74, 0x12, 0x50, 0x0a, 0x1a, 0x6d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65,
+	0x78, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x18, 0x6d, 0x65,
+	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x41, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x52, 0x0a, 0x1b, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52,
+	0x19, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x0a, 0x72, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0a, 0x72, 0x65, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x22, 0xea, 0x01, 0x0a, 0x0c, 0x53, 0x6f, 0x66, 0x74, 0x77,
+	0x61, 0x72, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x6e, 0x6f, 0x72, 0x6d, 0x61,
+	0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x46, 0x6f, 0x72, 0x6d,
+	0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x69, 0x6b, 0x69, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77, 0x69, 0x6b, 0x69, 0x64, 0x61, 0x74, 0x61, 0x49,
+	0x64, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x61, 0x77, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x61, 0x77, 0x46, 0x6f, 0x72, 0x6d, 0x12, 0x21, 0x0a, 0x0c,
+	0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0b, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x5f, 0x65, 0x6e, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x09, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x45, 0x6e, 0x64, 0x12, 0x33,
+	0x0a, 0x0e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x62, 0x6f, 0x78, 0x65, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x42, 0x6f, 0x78, 0x52, 0x0d, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x6f,
+	0x78, 0x65, 0x73, 0x22, 0xc4, 0x01, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x19, 0x0a, 0x08, 0x72, 0x61, 0x77, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x72, 0x61, 0x77, 0x46, 0x6f, 0x72, 0x6d, 0x12, 0x27, 0x0a, 0x0f, 0x6e, 0x6f,
+	0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x46,
+	0x6f, 0x72, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x5f, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x5f, 0x65, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x45, 0x6e, 0x64, 0x12, 0x33, 0x0a, 0x0e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x5f, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x42, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x6f, 0x78, 0x52, 0x0d, 0x62, 0x6f, 0x75,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x6f, 0x78, 0x65, 0x73, 0x22, 0x53, 0x0a, 0x0b, 0x42, 0x6f,
+	0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x6f, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x70, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x70, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x02, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02,
+	0x52, 0x01, 0x79, 0x12, 0x0c, 0x0a, 0x01, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x52, 0x01,
+	0x77, 0x12, 0x0c, 0x0a, 0x01, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x01, 0x68, 0x22,
+	0x7d, 0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62,
+	0x75, 0x74, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x52, 0x04,
+	0x75, 0x73, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x41, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x22, 0x37,
+	0x0a, 0x09, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02,
+	0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x22, 0x36, 0x0a, 0x09, 0x52, 0x65, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x65, 0x66, 0x5f, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x72, 0x65, 0x66, 0x4b, 0x65, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x74, 0x65, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x65, 0x69, 0x42,
+	0x0c, 0x5a, 0x0a, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x61, 0x70, 0x65, 0x72, 0x73, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -212,22 +727,38 @@ func file_papers_mentions_proto_rawDescGZIP() []byte {
 	return file_papers_mentions_proto_rawDescData
 }
 
-var file_papers_mentions_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_papers_mentions_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_papers_mentions_proto_goTypes = []any{
-	(*Mentions)(nil),     // 0: Mentions
-	(*Mention)(nil),      // 1: Mention
-	(*SoftwareName)(nil), // 2: SoftwareName
-	(*UUID)(nil),         // 3: UUID
+	(*Mentions)(nil),          // 0: Mentions
+	(*Mention)(nil),           // 1: Mention
+	(*SoftwareName)(nil),      // 2: SoftwareName
+	(*Version)(nil),           // 3: Version
+	(*BoundingBox)(nil),       // 4: BoundingBox
+	(*ContextAttributes)(nil), // 5: ContextAttributes
+	(*Attribute)(nil),         // 6: Attribute
+	(*Reference)(nil),         // 7: Reference
+	(*UUID)(nil),              // 8: UUID
 }
 var file_papers_mentions_proto_depIdxs = []int32{
-	3, // 0: Mentions.id:type_name -> UUID
-	1, // 1: Mentions.mentions:type_name -> Mention
-	2, // 2: Mention.software_name:type_name -> SoftwareName
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	8,  // 0: Mentions.id:type_name -> UUID
+	1,  // 1: Mentions.mentions:type_name -> Mention
+	2,  // 2: Mention.software_name:type_name -> SoftwareName
+	3,  // 3: Mention.version:type_name -> Version
+	2,  // 4: Mention.language:type_name -> SoftwareName
+	2,  // 5: Mention.url:type_name -> SoftwareName
+	5,  // 6: Mention.mention_context_attributes:type_name -> ContextAttributes
+	5,  // 7: Mention.document_context_attributes:type_name -> ContextAttributes
+	7,  // 8: Mention.references:type_name -> Reference
+	4,  // 9: SoftwareName.bounding_boxes:type_name -> BoundingBox
+	4,  // 10: Version.bounding_boxes:type_name -> BoundingBox
+	6,  // 11: ContextAttributes.used:type_name -> Attribute
+	6,  // 12: ContextAttributes.created:type_name -> Attribute
+	6,  // 13: ContextAttributes.shared:type_name -> Attribute
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_papers_mentions_proto_init() }
@@ -273,6 +804,66 @@ func file_papers_mentions_proto_init() {
 				return nil
 			}
 		}
+		file_papers_mentions_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*Version); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_papers_mentions_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*BoundingBox); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_papers_mentions_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ContextAttributes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_papers_mentions_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*Attribute); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_papers_mentions_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*Reference); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -280,7 +871,7 @@ func file_papers_mentions_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_papers_mentions_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
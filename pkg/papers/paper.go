@@ -0,0 +1,582 @@
+package papers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// PaperJson is a paper's metadata as provided by Unpaywall, with Crossref
+// metadata from biblio-glutton.
+type PaperJson struct {
+	Doi   string `json:"doi,omitempty"`
+	Year  int32  `json:"year,omitempty"`
+	Genre string `json:"genre,omitempty"`
+	IsOa  bool   `json:"is_oa,omitempty"`
+
+	Title   string `json:"title,omitempty"`
+	DoiUrl  string `json:"doi_url,omitempty"`
+	Updated string `json:"updated,omitempty"`
+
+	OaStatus  string `json:"oa_status,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+
+	ZAuthors []AuthorJson `json:"z_authors,omitempty"`
+
+	IsParatext  bool   `json:"is_paratext,omitempty"`
+	JournalName string `json:"journal_name,omitempty"`
+
+	OaLocations []OpenAccessLocationJson `json:"oa_locations,omitempty"`
+
+	DataStandard  int32  `json:"data_standard,omitempty"`
+	JournalIsOa   bool   `json:"journal_is_oa,omitempty"`
+	JournalIssns  string `json:"journal_issns,omitempty"`
+	JournalIssnL  string `json:"journal_issn_l,omitempty"`
+	PublishedDate string `json:"published_date,omitempty"`
+
+	BestOaLocation  *OpenAccessLocationJson `json:"best_oa_location,omitempty"`
+	FirstOaLocation *OpenAccessLocationJson `json:"first_oa_location,omitempty"`
+
+	JournalIsInDoaj      bool                     `json:"journal_is_in_doaj,omitempty"`
+	HasRepositoryCopy    bool                     `json:"has_repository_copy,omitempty"`
+	OaLocationsEmbargoed []OpenAccessLocationJson `json:"oa_locations_embargoed,omitempty"`
+
+	Id      string       `json:"id,omitempty"`
+	Glutton *GluttonJson `json:"glutton,omitempty"`
+
+	IstexId          string `json:"istexId,omitempty"`
+	ValidFulltextPdf bool   `json:"valid_fulltext_pdf,omitempty"`
+}
+
+type AuthorJson struct {
+	Given       string            `json:"given,omitempty"`
+	Family      string            `json:"family,omitempty"`
+	Sequence    string            `json:"sequence,omitempty"`
+	Affiliation []AffiliationJson `json:"affiliation,omitempty"`
+}
+
+type AffiliationJson struct {
+	Name string `json:"name"`
+}
+
+type OpenAccessLocationJson struct {
+	Url                   string `json:"url,omitempty"`
+	PmhId                 string `json:"pmh_id,omitempty"`
+	IsBest                bool   `json:"is_best,omitempty"`
+	License               string `json:"license,omitempty"`
+	OaDate                string `json:"oa_date,omitempty"`
+	Updated               string `json:"updated,omitempty"`
+	Version               string `json:"version,omitempty"`
+	Evidence              string `json:"evidence,omitempty"`
+	HostType              string `json:"host_type,omitempty"`
+	EndpointId            string `json:"endpoint_id,omitempty"`
+	UrlForPdf             string `json:"url_for_pdf,omitempty"`
+	UrlForLandingPage     string `json:"url_for_landing_page,omitempty"`
+	RepositoryInstitution string `json:"repository_institution,omitempty"`
+}
+
+// GluttonJson is the Crossref record biblio-glutton matched to the paper.
+// Keys and types follow Crossref's, so member IDs and issues are strings and
+// there may be many authors.
+type GluttonJson struct {
+	Url string `json:"URL,omitempty"`
+
+	Resource *ResourceJson `json:"resource,omitempty"`
+	Member   string        `json:"member,omitempty"`
+
+	Issn           []string `json:"ISSN,omitempty"`
+	ContainerTitle string   `json:"container-title,omitempty"`
+
+	Issued *DateJson    `json:"issued,omitempty"`
+	Author []AuthorJson `json:"author,omitempty"`
+
+	Doi                 string `json:"DOI,omitempty"`
+	IsReferencedByCount int32  `json:"is-referenced-by-count,omitempty"`
+
+	Published      *DateJson `json:"published,omitempty"`
+	PublishedPrint *DateJson `json:"published-print,omitempty"`
+
+	AlternativeId []string `json:"alternative-id,omitempty"`
+	Subject       []string `json:"subject,omitempty"`
+
+	ContentDomain *ContentDomainJson `json:"content-domain,omitempty"`
+	Title         []string           `json:"title,omitempty"`
+	Link          []LinkJson         `json:"link,omitempty"`
+
+	Source    string `json:"source,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+
+	JournalIssue    *JournalIssueJson `json:"journal-issue,omitempty"`
+	Volume          string            `json:"volume,omitempty"`
+	ReferencesCount int32             `json:"references-count,omitempty"`
+	IssnType        []IssnTypeJson    `json:"issn-type,omitempty"`
+	Language        string            `json:"language,omitempty"`
+
+	Page                string   `json:"page,omitempty"`
+	ShortContainerTitle []string `json:"short-container-title,omitempty"`
+	IstexId             string   `json:"istexId,omitempty"`
+	Ark                 string   `json:"ark,omitempty"`
+	OaLink              string   `json:"oaLink,omitempty"`
+}
+
+type ResourceJson struct {
+	Primary *LinkJson `json:"primary,omitempty"`
+}
+
+// DateJson is a Crossref partial date, such as [[2019, 4, 1]] or [[2019]].
+// Crossref nests the parts in an extra array; only the first is ever set.
+type DateJson struct {
+	DateParts [][]int32 `json:"date-parts,omitempty"`
+}
+
+type ContentDomainJson struct {
+	Domain               []string `json:"domain,omitempty"`
+	CrossmarkRestriction bool     `json:"crossmark-restriction,omitempty"`
+}
+
+// JournalIssueJson is the issue of a journal a paper appeared in. Issues
+// aren't always numbers, such as "S1" for a supplement.
+type JournalIssueJson struct {
+	Issue          string    `json:"issue,omitempty"`
+	PublishedPrint *DateJson `json:"published-print,omitempty"`
+}
+
+type IssnTypeJson struct {
+	Value string `json:"value,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+type LinkJson struct {
+	Url                 string `json:"URL,omitempty"`
+	ContentType         string `json:"content-type,omitempty"`
+	ContentVersion      string `json:"content-version,omitempty"`
+	IntendedApplication string `json:"intended-application,omitempty"`
+}
+
+var (
+	ErrParsePaperJson = errors.New("parsing Paper from JSON")
+	ErrLossy          = errors.New("converting to proto and back is lossy")
+)
+
+func (p *PaperJson) MarshalProto() (*Paper, error) {
+	x := &Paper{}
+
+	x.Doi = p.Doi
+	x.Year = p.Year
+	x.Genre = p.Genre
+	x.IsOa = p.IsOa
+
+	x.Title = p.Title
+	x.DoiUrl = p.DoiUrl
+	x.Updated = p.Updated
+
+	x.OaStatus = p.OaStatus
+	x.Publisher = p.Publisher
+
+	for _, author := range p.ZAuthors {
+		x.ZAuthors = append(x.ZAuthors, author.MarshalProto())
+	}
+
+	x.IsParatext = p.IsParatext
+	x.JournalName = p.JournalName
+
+	x.OaLocations = marshalOaLocations(p.OaLocations)
+
+	x.DataStandard = p.DataStandard
+	x.JournalIsOa = p.JournalIsOa
+	x.JournalIssns = p.JournalIssns
+	x.JournalIssnL = p.JournalIssnL
+	x.PublishedDate = p.PublishedDate
+
+	x.BestOaLocation = p.BestOaLocation.MarshalProto()
+	x.FirstOaLocation = p.FirstOaLocation.MarshalProto()
+
+	x.JournalIsInDoai = p.JournalIsInDoaj
+	x.HasRepositoryCopy = p.HasRepositoryCopy
+	x.OaLocationsEmbargoed = marshalOaLocations(p.OaLocationsEmbargoed)
+
+	x.Id = p.Id
+
+	var err error
+	x.Glutton, err = p.Glutton.MarshalProto()
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing glutton: %w", ErrParsePaperJson, err)
+	}
+
+	x.IstexId = p.IstexId
+	x.ValidFulltextPdf = p.ValidFulltextPdf
+
+	return x, nil
+}
+
+// CheckRoundTrip returns an error wrapping ErrLossy if converting x, the
+// result of p.MarshalProto, back to JSON doesn't give p.
+func (p *PaperJson) CheckRoundTrip(x *Paper) error {
+	p2 := &PaperJson{}
+	p2.UnmarshalProto(x)
+
+	// Absent and empty lists are indistinguishable once converted to proto.
+	if diff := cmp.Diff(p, p2, cmpopts.EquateEmpty()); diff != "" {
+		return fmt.Errorf("%w: %s", ErrLossy, diff)
+	}
+
+	return nil
+}
+
+func (p *PaperJson) UnmarshalProto(x *Paper) {
+	p.Doi = x.Doi
+	p.Year = x.Year
+	p.Genre = x.Genre
+	p.IsOa = x.IsOa
+
+	p.Title = x.Title
+	p.DoiUrl = x.DoiUrl
+	p.Updated = x.Updated
+
+	p.OaStatus = x.OaStatus
+	p.Publisher = x.Publisher
+
+	p.ZAuthors = nil
+	for _, author := range x.ZAuthors {
+		authorJson := AuthorJson{}
+		authorJson.UnmarshalProto(author)
+		p.ZAuthors = append(p.ZAuthors, authorJson)
+	}
+
+	p.IsParatext = x.IsParatext
+	p.JournalName = x.JournalName
+
+	p.OaLocations = unmarshalOaLocations(x.OaLocations)
+
+	p.DataStandard = x.DataStandard
+	p.JournalIsOa = x.JournalIsOa
+	p.JournalIssns = x.JournalIssns
+	p.JournalIssnL = x.JournalIssnL
+	p.PublishedDate = x.PublishedDate
+
+	p.BestOaLocation = unmarshalOaLocation(x.BestOaLocation)
+	p.FirstOaLocation = unmarshalOaLocation(x.FirstOaLocation)
+
+	p.JournalIsInDoaj = x.JournalIsInDoai
+	p.HasRepositoryCopy = x.HasRepositoryCopy
+	p.OaLocationsEmbargoed = unmarshalOaLocations(x.OaLocationsEmbargoed)
+
+	p.Id = x.Id
+
+	p.Glutton = nil
+	if x.Glutton != nil {
+		p.Glutton = &GluttonJson{}
+		p.Glutton.UnmarshalProto(x.Glutton)
+	}
+
+	p.IstexId = x.IstexId
+	p.ValidFulltextPdf = x.ValidFulltextPdf
+}
+
+func (a *AuthorJson) MarshalProto() *Author {
+	if a == nil {
+		return nil
+	}
+
+	x := &Author{
+		Given:    a.Given,
+		Family:   a.Family,
+		Sequence: a.Sequence,
+	}
+
+	for _, affiliation := range a.Affiliation {
+		x.Affiliation = append(x.Affiliation, affiliation.Name)
+	}
+
+	return x
+}
+
+func (a *AuthorJson) UnmarshalProto(x *Author) {
+	a.Given = x.Given
+	a.Family = x.Family
+	a.Sequence = x.Sequence
+
+	a.Affiliation = nil
+	for _, affiliation := range x.Affiliation {
+		a.Affiliation = append(a.Affiliation, AffiliationJson{Name: affiliation})
+	}
+}
+
+func (l *OpenAccessLocationJson) MarshalProto() *OpenAccessLocation {
+	if l == nil {
+		return nil
+	}
+
+	return &OpenAccessLocation{
+		Url:                   l.Url,
+		PmhId:                 l.PmhId,
+		IsBest:                l.IsBest,
+		License:               l.License,
+		OaDate:                l.OaDate,
+		Updated:               l.Updated,
+		Version:               l.Version,
+		Evidence:              l.Evidence,
+		HostType:              l.HostType,
+		EndpointId:            l.EndpointId,
+		UrlForPdf:             l.UrlForPdf,
+		UrlForLandingPage:     l.UrlForLandingPage,
+		RepositoryInstitution: l.RepositoryInstitution,
+	}
+}
+
+func (l *OpenAccessLocationJson) UnmarshalProto(x *OpenAccessLocation) {
+	l.Url = x.Url
+	l.PmhId = x.PmhId
+	l.IsBest = x.IsBest
+	l.License = x.License
+	l.OaDate = x.OaDate
+	l.Updated = x.Updated
+	l.Version = x.Version
+	l.Evidence = x.Evidence
+	l.HostType = x.HostType
+	l.EndpointId = x.EndpointId
+	l.UrlForPdf = x.UrlForPdf
+	l.UrlForLandingPage = x.UrlForLandingPage
+	l.RepositoryInstitution = x.RepositoryInstitution
+}
+
+func marshalOaLocations(locations []OpenAccessLocationJson) []*OpenAccessLocation {
+	var result []*OpenAccessLocation
+	for _, location := range locations {
+		result = append(result, location.MarshalProto())
+	}
+	return result
+}
+
+func unmarshalOaLocation(x *OpenAccessLocation) *OpenAccessLocationJson {
+	if x == nil {
+		return nil
+	}
+
+	result := &OpenAccessLocationJson{}
+	result.UnmarshalProto(x)
+	return result
+}
+
+func unmarshalOaLocations(xs []*OpenAccessLocation) []OpenAccessLocationJson {
+	var result []OpenAccessLocationJson
+	for _, x := range xs {
+		location := OpenAccessLocationJson{}
+		location.UnmarshalProto(x)
+		result = append(result, location)
+	}
+	return result
+}
+
+func (g *GluttonJson) MarshalProto() (*Glutton, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	x := &Glutton{}
+
+	x.Url = g.Url
+
+	if g.Resource != nil {
+		x.Resource = &Resource{Primary: g.Resource.Primary.MarshalProto()}
+	}
+	x.Member = g.Member
+
+	x.Issn = g.Issn
+	x.ContainerTitle = g.ContainerTitle
+
+	issued, err := g.Issued.MarshalProto()
+	if err != nil {
+		return nil, fmt.Errorf("issued: %w", err)
+	}
+	if issued != nil {
+		x.Issued = &Issued{DateParts: issued}
+	}
+	for _, author := range g.Author {
+		x.Author = append(x.Author, author.MarshalProto())
+	}
+
+	x.Doi = g.Doi
+	x.IsReferencedByCount = g.IsReferencedByCount
+
+	x.Published, err = g.Published.MarshalProto()
+	if err != nil {
+		return nil, fmt.Errorf("published: %w", err)
+	}
+	x.PublishedPrint, err = g.PublishedPrint.MarshalProto()
+	if err != nil {
+		return nil, fmt.Errorf("published-print: %w", err)
+	}
+
+	x.AlternativeId = g.AlternativeId
+	x.Subject = g.Subject
+
+	if g.ContentDomain != nil {
+		x.ContentDomain = &ContentDomain{
+			Domain:               g.ContentDomain.Domain,
+			CrossmarkRestriction: g.ContentDomain.CrossmarkRestriction,
+		}
+	}
+	x.Title = g.Title
+	for _, link := range g.Link {
+		x.Link = append(x.Link, link.MarshalProto())
+	}
+
+	x.Source = g.Source
+	x.Type = g.Type
+	x.Publisher = g.Publisher
+
+	if g.JournalIssue != nil {
+		x.JournalIssue = &JournalIssue{Issue: g.JournalIssue.Issue}
+		x.JournalIssue.PublishedPrint, err = g.JournalIssue.PublishedPrint.MarshalProto()
+		if err != nil {
+			return nil, fmt.Errorf("journal-issue: %w", err)
+		}
+	}
+	x.Volume = g.Volume
+	x.ReferencesCount = g.ReferencesCount
+	for _, issnType := range g.IssnType {
+		x.IssnType = append(x.IssnType, &IssnType{
+			Value: issnType.Value,
+			Type:  issnType.Type,
+		})
+	}
+	x.Language = g.Language
+
+	x.Page = g.Page
+	x.ShortContainerTitle = g.ShortContainerTitle
+	x.IstexId = g.IstexId
+	x.Ark = g.Ark
+	x.OaLink = g.OaLink
+
+	return x, nil
+}
+
+func (g *GluttonJson) UnmarshalProto(x *Glutton) {
+	g.Url = x.Url
+
+	g.Resource = nil
+	if x.Resource != nil {
+		g.Resource = &ResourceJson{Primary: unmarshalLink(x.Resource.Primary)}
+	}
+	g.Member = x.Member
+
+	g.Issn = x.Issn
+	g.ContainerTitle = x.ContainerTitle
+
+	g.Issued = nil
+	if x.Issued != nil {
+		g.Issued = unmarshalDate(x.Issued.DateParts)
+	}
+	g.Author = nil
+	for _, author := range x.Author {
+		authorJson := AuthorJson{}
+		authorJson.UnmarshalProto(author)
+		g.Author = append(g.Author, authorJson)
+	}
+
+	g.Doi = x.Doi
+	g.IsReferencedByCount = x.IsReferencedByCount
+
+	g.Published = unmarshalDate(x.Published)
+	g.PublishedPrint = unmarshalDate(x.PublishedPrint)
+
+	g.AlternativeId = x.AlternativeId
+	g.Subject = x.Subject
+
+	g.ContentDomain = nil
+	if x.ContentDomain != nil {
+		g.ContentDomain = &ContentDomainJson{
+			Domain:               x.ContentDomain.Domain,
+			CrossmarkRestriction: x.ContentDomain.CrossmarkRestriction,
+		}
+	}
+	g.Title = x.Title
+	g.Link = nil
+	for _, link := range x.Link {
+		g.Link = append(g.Link, *unmarshalLink(link))
+	}
+
+	g.Source = x.Source
+	g.Type = x.Type
+	g.Publisher = x.Publisher
+
+	g.JournalIssue = nil
+	if x.JournalIssue != nil {
+		g.JournalIssue = &JournalIssueJson{
+			Issue:          x.JournalIssue.Issue,
+			PublishedPrint: unmarshalDate(x.JournalIssue.PublishedPrint),
+		}
+	}
+	g.Volume = x.Volume
+	g.ReferencesCount = x.ReferencesCount
+	g.IssnType = nil
+	for _, issnType := range x.IssnType {
+		g.IssnType = append(g.IssnType, IssnTypeJson{
+			Value: issnType.Value,
+			Type:  issnType.Type,
+		})
+	}
+	g.Language = x.Language
+
+	g.Page = x.Page
+	g.ShortContainerTitle = x.ShortContainerTitle
+	g.IstexId = x.IstexId
+	g.Ark = x.Ark
+	g.OaLink = x.OaLink
+}
+
+func (d *DateJson) MarshalProto() (*Date, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	switch len(d.DateParts) {
+	case 0:
+		return &Date{}, nil
+	case 1:
+		return &Date{DateParts: d.DateParts[0]}, nil
+	default:
+		return nil, fmt.Errorf("%w: date has %d sets of date-parts but want at most 1", ErrParsePaperJson, len(d.DateParts))
+	}
+}
+
+func unmarshalDate(x *Date) *DateJson {
+	if x == nil {
+		return nil
+	}
+
+	if len(x.DateParts) == 0 {
+		return &DateJson{}
+	}
+
+	return &DateJson{DateParts: [][]int32{x.DateParts}}
+}
+
+func (l *LinkJson) MarshalProto() *Link {
+	if l == nil {
+		return nil
+	}
+
+	return &Link{
+		Url:                 l.Url,
+		ContentType:         l.ContentType,
+		ContentVersion:      l.ContentVersion,
+		IntendedApplication: l.IntendedApplication,
+	}
+}
+
+func unmarshalLink(x *Link) *LinkJson {
+	if x == nil {
+		return nil
+	}
+
+	return &LinkJson{
+		Url:                 x.Url,
+		ContentType:         x.ContentType,
+		ContentVersion:      x.ContentVersion,
+		IntendedApplication: x.IntendedApplication,
+	}
+}
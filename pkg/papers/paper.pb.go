@@ -496,11 +496,11 @@ type Glutton struct {
 
 	Url                 string         `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
 	Resource            *Resource      `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
-	Member              int32          `protobuf:"varint,3,opt,name=member,proto3" json:"member,omitempty"`
+	Member              string         `protobuf:"bytes,3,opt,name=member,proto3" json:"member,omitempty"`
 	Issn                []string       `protobuf:"bytes,4,rep,name=issn,proto3" json:"issn,omitempty"`
 	ContainerTitle      string         `protobuf:"bytes,5,opt,name=container_title,json=containerTitle,proto3" json:"container_title,omitempty"`
 	Issued              *Issued        `protobuf:"bytes,6,opt,name=issued,proto3" json:"issued,omitempty"`
-	Author              *Author        `protobuf:"bytes,7,opt,name=author,proto3" json:"author,omitempty"`
+	Author              []*Author      `protobuf:"bytes,7,rep,name=author,proto3" json:"author,omitempty"`
 	Doi                 string         `protobuf:"bytes,8,opt,name=doi,proto3" json:"doi,omitempty"`
 	IsReferencedByCount int32          `protobuf:"varint,9,opt,name=is_referenced_by_count,json=isReferencedByCount,proto3" json:"is_referenced_by_count,omitempty"`
 	Published           *Date          `protobuf:"bytes,10,opt,name=published,proto3" json:"published,omitempty"`
@@ -571,11 +571,11 @@ func (x *Glutton) GetResource() *Resource {
 	return nil
 }
 
-func (x *Glutton) GetMember() int32 {
+func (x *Glutton) GetMember() string {
 	if x != nil {
 		return x.Member
 	}
-	return 0
+	return ""
 }
 
 func (x *Glutton) GetIssn() []string {
@@ -599,7 +599,7 @@ func (x *Glutton) GetIssued() *Issued {
 	return nil
 }
 
-func (x *Glutton) GetAuthor() *Author {
+func (x *Glutton) GetAuthor() []*Author {
 	if x != nil {
 		return x.Author
 	}
@@ -961,8 +961,8 @@ type JournalIssue struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Issue          int32 `protobuf:"varint,1,opt,name=issue,proto3" json:"issue,omitempty"`
-	PublishedPrint *Date `protobuf:"bytes,2,opt,name=published_print,json=publishedPrint,proto3" json:"published_print,omitempty"`
+	Issue          string `protobuf:"bytes,1,opt,name=issue,proto3" json:"issue,omitempty"`
+	PublishedPrint *Date  `protobuf:"bytes,2,opt,name=published_print,json=publishedPrint,proto3" json:"published_print,omitempty"`
 }
 
 func (x *JournalIssue) Reset() {
@@ -997,11 +997,11 @@ func (*JournalIssue) Descriptor() ([]byte, []int) {
 	return file_papers_paper_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *JournalIssue) GetIssue() int32 {
+func (x *JournalIssue) GetIssue() string {
 	if x != nil {
 		return x.Issue
 	}
-	return 0
+	return ""
 }
 
 func (x *JournalIssue) GetPublishedPrint() *Date {
@@ -1242,14 +1242,14 @@ var file_papers_paper_proto_rawDesc = []byte{
 	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x52,
 	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
 	0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x73, 0x73,
+	0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x73, 0x73,
 	0x6e, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x69, 0x73, 0x73, 0x6e, 0x12, 0x27, 0x0a,
 	0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65,
 	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65,
 	0x72, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64,
 	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x64, 0x52,
 	0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f,
-	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x72, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72,
 	0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x6f, 0x69, 0x18,
 	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x64, 0x6f, 0x69, 0x12, 0x33, 0x0a, 0x16, 0x69, 0x73,
 	0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x5f, 0x63,
@@ -1311,7 +1311,7 @@ var file_papers_paper_proto_rawDesc = []byte{
 	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x63, 0x72, 0x6f, 0x73,
 	0x73, 0x6d, 0x61, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e,
 	0x22, 0x54, 0x0a, 0x0c, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x49, 0x73, 0x73, 0x75, 0x65,
-	0x12, 0x14, 0x0a, 0x05, 0x69, 0x73, 0x73, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x73, 0x73, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
 	0x05, 0x69, 0x73, 0x73, 0x75, 0x65, 0x12, 0x2e, 0x0a, 0x0f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73,
 	0x68, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
 	0x05, 0x2e, 0x44, 0x61, 0x74, 0x65, 0x52, 0x0e, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65,
@@ -0,0 +1,89 @@
+package papers
+
+import (
+	"bufio"
+	"encoding/json"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"testing"
+)
+
+// TestPaperJson_RoundTrip checks that sample records in the shape of the
+// Unpaywall dump, with Crossref metadata from biblio-glutton, survive
+// conversion to proto, serialization, and conversion back to JSON.
+func TestPaperJson_RoundTrip(t *testing.T) {
+	file, err := os.Open("testdata/papers.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	n := 0
+	for scanner.Scan() {
+		n++
+
+		entry := &PaperJson{}
+		err := json.Unmarshal(scanner.Bytes(), entry)
+		if err != nil {
+			t.Fatalf("record %d: %v", n, err)
+		}
+
+		x, err := entry.MarshalProto()
+		if err != nil {
+			t.Fatalf("record %d: %v", n, err)
+		}
+
+		// Check what would be read back from a .pbl file.
+		wire, err := proto.Marshal(x)
+		if err != nil {
+			t.Fatalf("record %d: %v", n, err)
+		}
+		read := &Paper{}
+		err = proto.Unmarshal(wire, read)
+		if err != nil {
+			t.Fatalf("record %d: %v", n, err)
+		}
+
+		err = entry.CheckRoundTrip(read)
+		if err != nil {
+			t.Errorf("record %d: %v", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Fatal("no sample records")
+	}
+}
+
+func TestGluttonJson_CrossrefTypes(t *testing.T) {
+	entry := &PaperJson{}
+	err := json.Unmarshal([]byte(`{"glutton":{
+		"member":"297",
+		"author":[{"given":"Ana","family":"Souza"},{"given":"Lee","family":"Park"}],
+		"journal-issue":{"issue":"S1"}
+	}}`), entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := entry.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := x.GetGlutton().GetMember(); got != "297" {
+		t.Errorf("got member %q, want %q", got, "297")
+	}
+	if got := len(x.GetGlutton().GetAuthor()); got != 2 {
+		t.Errorf("got %d authors, want 2", got)
+	}
+	if got := x.GetGlutton().GetJournalIssue().GetIssue(); got != "S1" {
+		t.Errorf("got issue %q, want %q", got, "S1")
+	}
+}
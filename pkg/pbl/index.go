@@ -0,0 +1,329 @@
+package pbl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+	"sort"
+)
+
+// IndexExt is appended to the path of a .pbl file to get the path of its
+// index.
+const IndexExt = ".idx"
+
+// tmpSuffix is appended to the path of an index until it is complete.
+const tmpSuffix = ".tmp"
+
+// An index file begins with a header of indexMagic and the big-endian size and
+// modification time, in Unix nanoseconds, of the .pbl file it was built from.
+// It is followed by fixed-size entries sorted by UUID. Each entry is the 16 raw
+// bytes of a paper UUID followed by the big-endian uint64 offset of its record
+// in the uncompressed .pbl stream. Entries with the same UUID appear in the
+// order of their records.
+const (
+	uuidSize        = 16
+	indexEntrySize  = uuidSize + 8
+	indexMagicSize  = 8
+	indexHeaderSize = indexMagicSize + 8 + 8
+)
+
+var indexMagic = []byte("PBLIDX02")
+
+var (
+	ErrIndex         = errors.New("indexing .pbl file")
+	ErrNotSeekable   = errors.New("stream does not support random access")
+	ErrNotFound      = errors.New("no record with id")
+	ErrInvalidUUID   = errors.New("UUID must be 16 bytes")
+	ErrInvalidHeader = errors.New("not a .pbl index")
+	ErrStaleIndex    = errors.New("index was built from a different version of the .pbl file")
+)
+
+// Keyed is a message identified by a paper UUID, such as papers.PaperId and
+// papers.Mentions.
+type Keyed interface {
+	proto.Message
+	GetId() *papers.UUID
+}
+
+// IndexPath returns the path of the index for the .pbl file at path.
+func IndexPath(path string) string {
+	return path + IndexExt
+}
+
+type IndexEntry struct {
+	Id     [uuidSize]byte
+	Offset int64
+}
+
+// BuildIndex reads every record in the .pbl file at path and writes the
+// sorted index to IndexPath(path). Returns the number of records indexed.
+//
+// Only uncompressed files can be indexed as offsets refer to positions in the
+// file on disk.
+func BuildIndex[T Keyed](path string, newT func() T) (int, error) {
+	reader, err := Open(path, newT)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrIndex, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	if reader.compressed {
+		return 0, fmt.Errorf("%w: %q is compressed: %w", ErrIndex, path, ErrNotSeekable)
+	}
+
+	source, err := reader.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("%w: reading stats of %q: %w", ErrIndex, path, err)
+	}
+
+	var entries []IndexEntry
+	for {
+		offset := reader.Offset()
+		msg, err := reader.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, fmt.Errorf("%w: %w", ErrIndex, err)
+		}
+
+		entry := IndexEntry{Offset: offset}
+		id := msg.GetId().GetId()
+		if len(id) != uuidSize {
+			return 0, fmt.Errorf("%w: record at offset %d: %w, got %d", ErrIndex, offset, ErrInvalidUUID, len(id))
+		}
+		copy(entry.Id[:], id)
+
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].Id[:], entries[j].Id[:]) < 0
+	})
+
+	err = writeIndexFile(IndexPath(path), source, entries)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
+
+// writeIndexFile writes the index to a temporary file and only renames it to
+// indexPath once complete. Otherwise an interrupted build could leave an index
+// with a valid header but missing entries, which OpenIndex would accept.
+func writeIndexFile(indexPath string, source os.FileInfo, entries []IndexEntry) error {
+	tmpPath := indexPath + tmpSuffix
+	indexFile, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("%w: creating %q: %w", ErrIndex, tmpPath, err)
+	}
+
+	err = WriteIndex(indexFile, source, entries)
+	if err != nil {
+		_ = indexFile.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("writing %q: %w", tmpPath, err)
+	}
+
+	err = indexFile.Sync()
+	if err != nil {
+		_ = indexFile.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("%w: syncing %q: %w", ErrIndex, tmpPath, err)
+	}
+
+	err = indexFile.Close()
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("%w: closing %q: %w", ErrIndex, tmpPath, err)
+	}
+
+	err = os.Rename(tmpPath, indexPath)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("%w: renaming %q: %w", ErrIndex, tmpPath, err)
+	}
+
+	return nil
+}
+
+// WriteIndex writes entries, which must already be sorted, in the index file
+// format for the .pbl file described by source.
+func WriteIndex(w io.Writer, source os.FileInfo, entries []IndexEntry) error {
+	writer := bufio.NewWriter(w)
+
+	_, err := writer.Write(sourceHeader(source))
+	if err != nil {
+		return fmt.Errorf("%w: writing header: %w", ErrIndex, err)
+	}
+
+	var buf [indexEntrySize]byte
+	for _, entry := range entries {
+		copy(buf[:uuidSize], entry.Id[:])
+		binary.BigEndian.PutUint64(buf[uuidSize:], uint64(entry.Offset))
+
+		_, err = writer.Write(buf[:])
+		if err != nil {
+			return fmt.Errorf("%w: writing entry: %w", ErrIndex, err)
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("%w: flushing: %w", ErrIndex, err)
+	}
+
+	return nil
+}
+
+// sourceHeader is the index header for the .pbl file described by source.
+func sourceHeader(source os.FileInfo) []byte {
+	header := make([]byte, 0, indexHeaderSize)
+	header = append(header, indexMagic...)
+	header = binary.BigEndian.AppendUint64(header, uint64(source.Size()))
+	header = binary.BigEndian.AppendUint64(header, uint64(source.ModTime().UnixNano()))
+	return header
+}
+
+// Index is an open index file. Lookups binary search the file on disk rather
+// than loading it into memory.
+type Index struct {
+	file *os.File
+	n    int64
+}
+
+// OpenIndex opens the index file at path for the .pbl file described by
+// source. Returns an error wrapping ErrStaleIndex if the .pbl file has changed
+// since the index was built, as its offsets can no longer be trusted.
+func OpenIndex(path string, source os.FileInfo) (*Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: opening %q: %w", ErrIndex, path, err)
+	}
+
+	stats, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("%w: reading stats of %q: %w", ErrIndex, path, err)
+	}
+
+	header := make([]byte, indexHeaderSize)
+	_, err = file.ReadAt(header, 0)
+	if err != nil || !bytes.Equal(header[:indexMagicSize], indexMagic) {
+		_ = file.Close()
+		return nil, fmt.Errorf("%w: %q: %w", ErrIndex, path, ErrInvalidHeader)
+	}
+
+	if !bytes.Equal(header, sourceHeader(source)) {
+		_ = file.Close()
+		return nil, fmt.Errorf("%w: %q: %w; rebuild it", ErrIndex, path, ErrStaleIndex)
+	}
+
+	size := stats.Size() - indexHeaderSize
+	if size%indexEntrySize != 0 {
+		_ = file.Close()
+		return nil, fmt.Errorf("%w: %q: %w: %d trailing bytes", ErrIndex, path, ErrTruncated, size%indexEntrySize)
+	}
+
+	return &Index{
+		file: file,
+		n:    size / indexEntrySize,
+	}, nil
+}
+
+// Len is the number of entries in the index.
+func (idx *Index) Len() int64 {
+	return idx.n
+}
+
+// Find returns the offset of the first record with the UUID id.
+// Returns an error wrapping ErrNotFound if there is no such record.
+func (idx *Index) Find(id []byte) (int64, error) {
+	if len(id) != uuidSize {
+		return 0, fmt.Errorf("%w: %w, got %d", ErrIndex, ErrInvalidUUID, len(id))
+	}
+
+	var buf [indexEntrySize]byte
+	var readErr error
+	// Find the first entry whose UUID is not less than id.
+	i := sort.Search(int(idx.n), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+		_, readErr = idx.file.ReadAt(buf[:uuidSize], indexHeaderSize+int64(i)*indexEntrySize)
+		return bytes.Compare(buf[:uuidSize], id) >= 0
+	})
+	if readErr != nil {
+		return 0, fmt.Errorf("%w: reading entry: %w", ErrIndex, readErr)
+	}
+
+	if int64(i) == idx.n {
+		return 0, ErrNotFound
+	}
+
+	_, err := idx.file.ReadAt(buf[:], indexHeaderSize+int64(i)*indexEntrySize)
+	if err != nil {
+		return 0, fmt.Errorf("%w: reading entry: %w", ErrIndex, err)
+	}
+
+	if !bytes.Equal(buf[:uuidSize], id) {
+		return 0, ErrNotFound
+	}
+
+	return int64(binary.BigEndian.Uint64(buf[uuidSize:])), nil
+}
+
+func (idx *Index) Close() error {
+	return idx.file.Close()
+}
+
+// Lookup returns the first record with the UUID id using the index at
+// IndexPath, which is opened on first use. Lookup does not change the position
+// of Read, so point queries and a sequential scan may be interleaved.
+//
+// Only Readers created by Open for uncompressed files support Lookup.
+func (r *Reader[T]) Lookup(id *papers.UUID) (T, error) {
+	var zero T
+
+	if r.file == nil || r.compressed {
+		return zero, ErrNotSeekable
+	}
+
+	if r.index == nil {
+		source, err := r.file.Stat()
+		if err != nil {
+			return zero, fmt.Errorf("%w: reading stats of %q: %w", ErrIndex, r.path, err)
+		}
+
+		index, err := OpenIndex(IndexPath(r.path), source)
+		if err != nil {
+			return zero, err
+		}
+		r.index = index
+	}
+
+	offset, err := r.index.Find(id.GetId())
+	if err != nil {
+		return zero, err
+	}
+
+	section := io.NewSectionReader(r.file, offset, 1<<63-1-offset)
+	msg, _, err := r.readRecord(bufio.NewReader(section), offset)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return zero, fmt.Errorf("%w: %w: index points past end of file at offset %d", ErrRead, ErrTruncated, offset)
+		}
+		return zero, err
+	}
+
+	return msg, nil
+}
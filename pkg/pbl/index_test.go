@@ -0,0 +1,194 @@
+package pbl
+
+import (
+	"errors"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes records to a new file at path.
+func writeFile(t *testing.T, path string, records []*papers.PaperId) {
+	t.Helper()
+
+	writer, err := Create[*papers.PaperId](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		err = writer.Write(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func uuid(id byte) *papers.UUID {
+	return paperIds(id)[0].GetId()
+}
+
+func TestBuildIndex_Lookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids"+Ext)
+	// UUID 1 appears twice, at records 1 and 3.
+	writeFile(t, path, paperIds(3, 1, 2, 1))
+
+	n, err := BuildIndex(path, newPaperId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("got %d records indexed, want 4", n)
+	}
+	if _, err := os.Stat(IndexPath(path) + tmpSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got temporary index left behind: %v", err)
+	}
+
+	reader, err := Open(path, newPaperId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	tcs := []struct {
+		name    string
+		id      byte
+		want    string
+		wantErr error
+	}{{
+		name: "first record",
+		id:   3,
+		want: "10.5555/0",
+	}, {
+		name: "last unique record",
+		id:   2,
+		want: "10.5555/2",
+	}, {
+		name: "duplicate returns the first",
+		id:   1,
+		want: "10.5555/1",
+	}, {
+		name:    "missing",
+		id:      9,
+		wantErr: ErrNotFound,
+	}, {
+		name:    "before all entries",
+		id:      0,
+		wantErr: ErrNotFound,
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := reader.Lookup(uuid(tc.id))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if got.GetDoi() != tc.want {
+				t.Errorf("got record %q, want %q", got.GetDoi(), tc.want)
+			}
+		})
+	}
+
+	// Lookups don't disturb a sequential scan.
+	got, _, err := dois(t, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Errorf("got %d records reading after lookups, want 4", len(got))
+	}
+}
+
+func TestIndex_Find(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids"+Ext)
+	records := paperIds(3, 1, 2, 1)
+	writeFile(t, path, records)
+	_, offsets := encode(t, records)
+
+	_, err := BuildIndex(path, newPaperId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := OpenIndex(IndexPath(path), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = index.Close()
+	}()
+
+	// offsets holds the end of each record, so the duplicate UUID 1 should
+	// point to the end of the first record.
+	got, err := index.Find(uuid(1).GetId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != offsets[0] {
+		t.Errorf("got offset %d for duplicate UUID, want first at %d", got, offsets[0])
+	}
+
+	_, err = index.Find([]byte{1})
+	if !errors.Is(err, ErrInvalidUUID) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidUUID)
+	}
+}
+
+func TestLookup_StaleIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids"+Ext)
+	writeFile(t, path, paperIds(1, 2))
+
+	_, err := BuildIndex(path, newPaperId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite the file with a record added, without rebuilding the index.
+	writeFile(t, path, paperIds(0, 1, 2))
+
+	reader, err := Open(path, newPaperId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	_, err = reader.Lookup(uuid(1))
+	if !errors.Is(err, ErrStaleIndex) {
+		t.Errorf("got error %v, want %v", err, ErrStaleIndex)
+	}
+}
+
+func TestIndex_Compressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids"+Ext+GzipExt)
+	writeFile(t, path, paperIds(1, 2))
+
+	_, err := BuildIndex(path, newPaperId)
+	if !errors.Is(err, ErrNotSeekable) {
+		t.Errorf("got error %v building index, want %v", err, ErrNotSeekable)
+	}
+
+	reader, err := Open(path, newPaperId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	_, err = reader.Lookup(uuid(1))
+	if !errors.Is(err, ErrNotSeekable) {
+		t.Errorf("got error %v looking up, want %v", err, ErrNotSeekable)
+	}
+}
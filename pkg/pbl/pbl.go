@@ -0,0 +1,52 @@
+// Package pbl reads and writes .pbl files: streams of protocol buffers, each
+// prefixed with its length in bytes encoded as a uvarint.
+package pbl
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	Ext = ".pbl"
+
+	GzipExt = ".gz"
+	ZstdExt = ".zst"
+)
+
+var (
+	ErrRead      = errors.New("reading .pbl stream")
+	ErrWrite     = errors.New("writing .pbl stream")
+	ErrTruncated = errors.New("truncated record")
+)
+
+// Compression is the codec applied to an entire .pbl stream.
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZstd
+)
+
+// HasExt returns true if path names a .pbl file, optionally compressed.
+// For example "ids.pbl", "ids.pbl.gz", and "ids.pbl.zst".
+func HasExt(path string) bool {
+	path = strings.TrimSuffix(path, GzipExt)
+	path = strings.TrimSuffix(path, ZstdExt)
+
+	return filepath.Ext(path) == Ext
+}
+
+// CompressionOf returns the codec implied by the extension of path.
+func CompressionOf(path string) Compression {
+	switch {
+	case strings.HasSuffix(path, GzipExt):
+		return CompressionGzip
+	case strings.HasSuffix(path, ZstdExt):
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
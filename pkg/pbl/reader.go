@@ -0,0 +1,254 @@
+package pbl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	bondsmith "github.com/willbeason/bondsmith"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"iter"
+	"math"
+	"os"
+)
+
+// maxRecordSize is the largest record length Reader accepts. Protocol buffers
+// are limited to 2 GiB, so a longer length means the stream is corrupt.
+const maxRecordSize = math.MaxInt32
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Reader reads length-delimited records of type T.
+type Reader[T proto.Message] struct {
+	// source counts the bytes read from the underlying, possibly compressed,
+	// stream. Used for reporting progress.
+	source *bondsmith.CountReader
+	reader *bufio.Reader
+
+	closers []io.Closer
+
+	// path and file are set when the Reader was created by Open, and
+	// compressed is set if the stream required decompression. Random access
+	// with Lookup is only possible for uncompressed files.
+	path       string
+	file       *os.File
+	compressed bool
+	index      *Index
+
+	// size is the size of the uncompressed stream, or -1 if unknown. Records
+	// claiming to extend past it are truncated.
+	size int64
+
+	newT func() T
+
+	// buf is reused between records to avoid an allocation per message.
+	buf []byte
+
+	offset int64
+}
+
+// NewReader returns a Reader of the records in r. Gzip and zstd streams are
+// detected by their magic bytes and decompressed transparently.
+//
+// newT is called once per record. Since proto.Unmarshal resets the message
+// it is given, newT may return the same message every time if callers do not
+// retain records between iterations.
+func NewReader[T proto.Message](r io.Reader, newT func() T) (*Reader[T], error) {
+	source := bondsmith.NewCountReader(r)
+	reader := bufio.NewReader(source)
+
+	result := &Reader[T]{
+		source: source,
+		reader: reader,
+		newT:   newT,
+		size:   -1,
+	}
+
+	// Peek returns fewer bytes and io.EOF for streams shorter than the longest
+	// magic number, which are necessarily uncompressed.
+	magic, err := reader.Peek(len(zstdMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: detecting compression: %w", ErrRead, err)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%w: starting gzip reader stream: %w", ErrRead, err)
+		}
+		result.reader = bufio.NewReader(gzipReader)
+		result.closers = append(result.closers, gzipReader)
+		result.compressed = true
+	case bytes.HasPrefix(magic, zstdMagic):
+		zstdReader, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%w: starting zstd reader stream: %w", ErrRead, err)
+		}
+		result.reader = bufio.NewReader(zstdReader)
+		result.closers = append(result.closers, zstdReader.IOReadCloser())
+		result.compressed = true
+	}
+
+	return result, nil
+}
+
+// Open opens the file at path and returns a Reader of its records. Close
+// closes the file.
+func Open[T proto.Message](path string, newT func() T) (*Reader[T], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: opening %q: %w", ErrRead, path, err)
+	}
+
+	result, err := NewReader(file, newT)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("reading %q: %w", path, err)
+	}
+	result.closers = append(result.closers, file)
+	result.path = path
+	result.file = file
+
+	if !result.compressed {
+		stats, err := file.Stat()
+		if err != nil {
+			_ = result.Close()
+			return nil, fmt.Errorf("%w: reading stats of %q: %w", ErrRead, path, err)
+		}
+		result.size = stats.Size()
+	}
+
+	return result, nil
+}
+
+// Read returns the sequence of records in the stream. The sequence ends
+// without error at a clean end of stream; a stream which ends partway through
+// a record yields an error wrapping ErrTruncated.
+func (r *Reader[T]) Read() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			msg, err := r.next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					var zero T
+					yield(zero, err)
+				}
+				return
+			}
+
+			if !yield(msg, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (r *Reader[T]) next() (T, error) {
+	msg, n, err := r.readRecord(r.reader, r.offset)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	r.offset += n
+
+	return msg, nil
+}
+
+// readRecord reads the record beginning at offset from reader, returning it
+// and the number of bytes it occupies in the stream.
+func (r *Reader[T]) readRecord(reader *bufio.Reader, offset int64) (T, int64, error) {
+	var zero T
+
+	nProtoBytes, err := binary.ReadUvarint(reader)
+	if err != nil {
+		switch {
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return zero, 0, fmt.Errorf("%w: %w: reading proto length at offset %d", ErrRead, ErrTruncated, offset)
+		case errors.Is(err, io.EOF):
+			// ReadUvarint only returns io.EOF if no bytes were read.
+			return zero, 0, io.EOF
+		default:
+			return zero, 0, fmt.Errorf("%w: reading proto length at offset %d: %w", ErrRead, offset, err)
+		}
+	}
+
+	// Check the length before allocating so a corrupt stream can't demand
+	// more memory than the record could possibly need.
+	if nProtoBytes > maxRecordSize {
+		return zero, 0, fmt.Errorf("%w: proto length %d at offset %d exceeds %d bytes", ErrRead, nProtoBytes, offset, maxRecordSize)
+	}
+	end := offset + int64(uvarintSize(nProtoBytes)) + int64(nProtoBytes)
+	if r.size >= 0 && end > r.size {
+		return zero, 0, fmt.Errorf("%w: %w: want %d bytes for proto at offset %d, but stream ends at %d", ErrRead, ErrTruncated, nProtoBytes, offset, r.size)
+	}
+
+	if uint64(cap(r.buf)) < nProtoBytes {
+		r.buf = make([]byte, nProtoBytes)
+	}
+	r.buf = r.buf[:nProtoBytes]
+
+	_, err = io.ReadFull(reader, r.buf)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return zero, 0, fmt.Errorf("%w: %w: want %d bytes for proto at offset %d", ErrRead, ErrTruncated, nProtoBytes, offset)
+		}
+		return zero, 0, fmt.Errorf("%w: reading proto at offset %d: %w", ErrRead, offset, err)
+	}
+
+	msg := r.newT()
+	err = proto.Unmarshal(r.buf, msg)
+	if err != nil {
+		return zero, 0, fmt.Errorf("%w: unmarshalling proto at offset %d: %w", ErrRead, offset, err)
+	}
+
+	return msg, end - offset, nil
+}
+
+// uvarintSize is the number of bytes binary.PutUvarint uses to encode x.
+func uvarintSize(x uint64) int {
+	n := 1
+	for x >= 0x80 {
+		x >>= 7
+		n++
+	}
+	return n
+}
+
+// Offset is the position in the uncompressed stream of the next record.
+func (r *Reader[T]) Offset() int64 {
+	return r.offset
+}
+
+// Consumed is the number of bytes read so far from the underlying stream,
+// before decompression. Compare with the size of the file being read to
+// report progress. Reads are buffered, so Consumed may run ahead of the
+// records returned so far.
+func (r *Reader[T]) Consumed() int64 {
+	return int64(r.source.Count())
+}
+
+// Close closes the decompressor, index, and file opened by Open, if any.
+func (r *Reader[T]) Close() error {
+	var err error
+	if r.index != nil {
+		err = r.index.Close()
+	}
+
+	for _, closer := range r.closers {
+		closeErr := closer.Close()
+		if closeErr != nil && err == nil {
+			err = fmt.Errorf("%w: closing: %w", ErrRead, closeErr)
+		}
+	}
+
+	return err
+}
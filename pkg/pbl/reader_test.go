@@ -0,0 +1,269 @@
+package pbl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+	"github.com/willbeason/software-mentions/pkg/papers"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newPaperId() *papers.PaperId {
+	return &papers.PaperId{}
+}
+
+// paperIds returns n records with UUIDs from ids and DOIs "10.5555/0",
+// "10.5555/1", and so on.
+func paperIds(ids ...byte) []*papers.PaperId {
+	var result []*papers.PaperId
+	for i, id := range ids {
+		uuid := make([]byte, uuidSize)
+		uuid[uuidSize-1] = id
+		result = append(result, &papers.PaperId{
+			Id:  &papers.UUID{Id: uuid},
+			Doi: fmt.Sprint("10.5555/", i),
+		})
+	}
+	return result
+}
+
+// encode writes records with a Writer, returning the stream and the offset
+// after each record.
+func encode(t *testing.T, records []*papers.PaperId) ([]byte, []int64) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := NewWriter[*papers.PaperId](&buf)
+	var offsets []int64
+	for _, record := range records {
+		err := writer.Write(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, writer.Offset())
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if writer.Offset() != int64(buf.Len()) {
+		t.Errorf("got writer offset %d, want %d", writer.Offset(), buf.Len())
+	}
+
+	return buf.Bytes(), offsets
+}
+
+// dois reads every record, returning their DOIs and the reader's offset after
+// each record.
+func dois(t *testing.T, reader *Reader[*papers.PaperId]) ([]string, []int64, error) {
+	t.Helper()
+
+	var got []string
+	var offsets []int64
+	for record, err := range reader.Read() {
+		if err != nil {
+			return got, offsets, err
+		}
+		got = append(got, record.GetDoi())
+		offsets = append(offsets, reader.Offset())
+	}
+
+	return got, offsets, nil
+}
+
+func gzipped(t *testing.T, stream []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstded(t *testing.T, stream []byte) []byte {
+	t.Helper()
+
+	writer, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = writer.Close()
+	}()
+	return writer.EncodeAll(stream, nil)
+}
+
+func TestReader(t *testing.T) {
+	stream, offsets := encode(t, paperIds(1, 2, 3))
+	all := []string{"10.5555/0", "10.5555/1", "10.5555/2"}
+
+	tcs := []struct {
+		name        string
+		input       []byte
+		want        []string
+		wantOffsets []int64
+		wantErr     error
+	}{{
+		name:        "uncompressed",
+		input:       stream,
+		want:        all,
+		wantOffsets: offsets,
+	}, {
+		name:        "gzip",
+		input:       gzipped(t, stream),
+		want:        all,
+		wantOffsets: offsets,
+	}, {
+		name:        "zstd",
+		input:       zstded(t, stream),
+		want:        all,
+		wantOffsets: offsets,
+	}, {
+		name:  "empty",
+		input: nil,
+	}, {
+		name:        "cut-off length",
+		input:       append(bytes.Clone(stream), 0x80),
+		want:        all,
+		wantOffsets: offsets,
+		wantErr:     ErrTruncated,
+	}, {
+		name:        "cut-off body",
+		input:       stream[:len(stream)-1],
+		want:        all[:2],
+		wantOffsets: offsets[:2],
+		wantErr:     ErrTruncated,
+	}, {
+		name:        "cut-off body in gzip",
+		input:       gzipped(t, stream[:len(stream)-1]),
+		want:        all[:2],
+		wantOffsets: offsets[:2],
+		wantErr:     ErrTruncated,
+	}, {
+		name:        "corrupt length",
+		input:       binary.AppendUvarint(bytes.Clone(stream), 1<<63),
+		want:        all,
+		wantOffsets: offsets,
+		wantErr:     ErrRead,
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			reader, err := NewReader(bytes.NewReader(tc.input), newPaperId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = reader.Close()
+			}()
+
+			got, gotOffsets, err := dois(t, reader)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("got error %v, want %v", err, tc.wantErr)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tc.wantOffsets, gotOffsets); diff != "" {
+				t.Errorf("offsets differ:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreate_Open(t *testing.T) {
+	records := paperIds(1, 2, 3)
+
+	for _, ext := range []string{Ext, Ext + GzipExt, Ext + ZstdExt} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ids"+ext)
+
+			writer, err := Create[*papers.PaperId](path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, record := range records {
+				err = writer.Write(record)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			err = writer.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := Open(path, newPaperId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = reader.Close()
+			}()
+
+			got, _, err := dois(t, reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]string{"10.5555/0", "10.5555/1", "10.5555/2"}, got); diff != "" {
+				t.Error(diff)
+			}
+
+			if reader.Offset() != writer.Offset() {
+				t.Errorf("got offset %d after reading, want %d", reader.Offset(), writer.Offset())
+			}
+
+			stats, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reader.Consumed() != stats.Size() {
+				t.Errorf("consumed %d bytes, want all %d", reader.Consumed(), stats.Size())
+			}
+		})
+	}
+}
+
+// TestOpen_LengthPastEnd checks that a record claiming to be longer than the
+// rest of the file is reported as truncated without reading it.
+func TestOpen_LengthPastEnd(t *testing.T) {
+	stream, _ := encode(t, paperIds(1))
+	stream = binary.AppendUvarint(stream, 1000)
+
+	path := filepath.Join(t.TempDir(), "ids"+Ext)
+	err := os.WriteFile(path, stream, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := Open(path, newPaperId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	got, _, err := dois(t, reader)
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("got error %v, want %v", err, ErrTruncated)
+	}
+	if diff := cmp.Diff([]string{"10.5555/0"}, got); diff != "" {
+		t.Error(diff)
+	}
+}
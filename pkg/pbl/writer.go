@@ -0,0 +1,116 @@
+package pbl
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+)
+
+// Writer writes messages of type T as length-delimited records.
+type Writer[T proto.Message] struct {
+	writer *bufio.Writer
+
+	// closers are closed in order by Close after flushing writer.
+	closers []io.Closer
+
+	// buf is reused between records to avoid an allocation per message.
+	buf []byte
+
+	offset int64
+}
+
+// NewWriter returns a Writer which writes uncompressed records to w.
+// Close flushes buffered records but does not close w.
+func NewWriter[T proto.Message](w io.Writer) *Writer[T] {
+	return &Writer[T]{writer: bufio.NewWriter(w)}
+}
+
+// Create creates the file at path and returns a Writer to it. The stream is
+// compressed according to the extension of path, as determined by
+// CompressionOf.
+func Create[T proto.Message](path string) (*Writer[T], error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating %q: %w", ErrWrite, path, err)
+	}
+
+	var w io.Writer = file
+	var closers []io.Closer
+	switch CompressionOf(path) {
+	case CompressionGzip:
+		gzipWriter := gzip.NewWriter(file)
+		w = gzipWriter
+		closers = append(closers, gzipWriter)
+	case CompressionZstd:
+		zstdWriter, err := zstd.NewWriter(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("%w: starting zstd stream for %q: %w", ErrWrite, path, err)
+		}
+		w = zstdWriter
+		closers = append(closers, zstdWriter)
+	}
+	closers = append(closers, file)
+
+	result := NewWriter[T](w)
+	result.closers = closers
+
+	return result, nil
+}
+
+// Write appends msg to the stream.
+func (w *Writer[T]) Write(msg T) error {
+	var err error
+	w.buf, err = proto.MarshalOptions{}.MarshalAppend(w.buf[:0], msg)
+	if err != nil {
+		return fmt.Errorf("%w: marshalling proto: %w", ErrWrite, err)
+	}
+
+	var sizeBuf [binary.MaxVarintLen64]byte
+	nSizeBytes := binary.PutUvarint(sizeBuf[:], uint64(len(w.buf)))
+
+	_, err = w.writer.Write(sizeBuf[:nSizeBytes])
+	if err != nil {
+		return fmt.Errorf("%w: writing proto length: %w", ErrWrite, err)
+	}
+
+	_, err = w.writer.Write(w.buf)
+	if err != nil {
+		return fmt.Errorf("%w: writing proto: %w", ErrWrite, err)
+	}
+
+	w.offset += int64(nSizeBytes + len(w.buf))
+
+	return nil
+}
+
+// Offset is the number of uncompressed bytes written so far, and so the
+// offset the next record will begin at.
+func (w *Writer[T]) Offset() int64 {
+	return w.offset
+}
+
+// Close flushes any buffered records and closes the compressor and file
+// opened by Create, if any.
+func (w *Writer[T]) Close() error {
+	var err error
+	flushErr := w.writer.Flush()
+	if flushErr != nil {
+		err = fmt.Errorf("%w: flushing: %w", ErrWrite, flushErr)
+	}
+
+	// Close everything even if flushing fails so we don't leak files.
+	for _, closer := range w.closers {
+		closeErr := closer.Close()
+		if closeErr != nil && err == nil {
+			err = fmt.Errorf("%w: closing: %w", ErrWrite, closeErr)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,219 @@
+// Package progress reports how far along long-running commands are. On a
+// terminal it draws progress bars. Otherwise, such as under nohup, cron, or
+// with output redirected to a file, it periodically logs structured lines with
+// the rate and estimated time remaining.
+package progress
+
+import (
+	"github.com/vbauerster/mpb"
+	"github.com/vbauerster/mpb/decor"
+	"golang.org/x/crypto/ssh/terminal"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultInterval is how often bars log their progress when not drawn.
+const DefaultInterval = 30 * time.Second
+
+// Unit is what a Bar counts.
+type Unit string
+
+const (
+	Files   Unit = "files"
+	Records Unit = "records"
+	Bytes   Unit = "bytes"
+)
+
+// Progress is a set of bars for the tasks of one command.
+type Progress struct {
+	// bars draws progress on the terminal, or is nil if progress is logged.
+	bars *mpb.Progress
+
+	interval time.Duration
+}
+
+// New draws bars if stdout is a terminal and logs progress every interval
+// otherwise. If quiet, progress is neither drawn nor logged.
+func New(quiet bool, interval time.Duration) *Progress {
+	switch {
+	case quiet:
+		return &Progress{bars: mpb.New(mpb.WithOutput(io.Discard))}
+	case !terminal.IsTerminal(int(os.Stdout.Fd())):
+		return &Progress{interval: interval}
+	}
+
+	width, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		// Fall back to mpb's default width.
+		return &Progress{bars: mpb.New()}
+	}
+
+	return &Progress{bars: mpb.New(mpb.WithWidth(width))}
+}
+
+// Wait blocks until every drawn bar is complete.
+func (p *Progress) Wait() {
+	if p.bars != nil {
+		p.bars.Wait()
+	}
+}
+
+// Bar tracks progress towards a known total. It is safe to increment from
+// multiple goroutines.
+type Bar struct {
+	name  string
+	unit  Unit
+	total int64
+	start time.Time
+
+	current atomic.Int64
+
+	// bar is nil if progress is logged.
+	bar *mpb.Bar
+	// subtask is whether the bar is part of a larger task, so its completion
+	// is only worth logging when debugging.
+	subtask bool
+
+	interval time.Duration
+	// mu guards lastLog.
+	mu      sync.Mutex
+	lastLog time.Time
+}
+
+// BarOption configures how a bar is drawn.
+type BarOption func(*barOptions)
+
+type barOptions struct {
+	removeOnComplete bool
+}
+
+// RemoveOnComplete hides the bar once it is complete, such as for bars of
+// subtasks. When logging, completion is only logged at debug level.
+func RemoveOnComplete(o *barOptions) {
+	o.removeOnComplete = true
+}
+
+// AddBar starts tracking a task named name, which is done once total units
+// are complete. name may be empty for the main task of a command.
+func (p *Progress) AddBar(name string, total int64, unit Unit, options ...BarOption) *Bar {
+	o := &barOptions{}
+	for _, option := range options {
+		option(o)
+	}
+
+	now := time.Now()
+	b := &Bar{
+		name:     name,
+		unit:     unit,
+		total:    total,
+		start:    now,
+		subtask:  o.removeOnComplete,
+		interval: p.interval,
+		lastLog:  now,
+	}
+
+	if p.bars == nil {
+		return b
+	}
+
+	var prepend []decor.Decorator
+	if name != "" {
+		prepend = append(prepend, decor.Name(name))
+	}
+	switch unit {
+	case Bytes:
+		prepend = append(prepend, decor.AverageSpeed(decor.UnitKiB, "%.1f"))
+	default:
+		prepend = append(prepend, decor.CountersNoUnit("%3d/%3d", decor.WCSyncSpace))
+	}
+
+	barOptions := []mpb.BarOption{
+		mpb.PrependDecorators(prepend...),
+		mpb.AppendDecorators(decor.AverageETA(decor.ET_STYLE_HHMMSS)),
+	}
+	if o.removeOnComplete {
+		barOptions = append(barOptions, mpb.BarRemoveOnComplete())
+	}
+
+	b.bar = p.bars.AddBar(total, barOptions...)
+
+	return b
+}
+
+// Increment records one more unit of progress.
+func (b *Bar) Increment() {
+	b.IncrBy(1)
+}
+
+// IncrBy records n more units of progress.
+func (b *Bar) IncrBy(n int) {
+	current := b.current.Add(int64(n))
+	elapsed := time.Since(b.start)
+
+	if b.bar != nil {
+		b.bar.IncrBy(n, elapsed)
+		return
+	}
+
+	complete := current >= b.total
+	if !b.shouldLog(complete) {
+		return
+	}
+
+	rate := 0.0
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate = float64(current) / seconds
+	}
+
+	attrs := []any{
+		"task", b.name,
+		string(b.unit), current,
+		"total", b.total,
+		string(b.unit) + "_per_sec", int64(rate),
+		"elapsed", elapsed.Round(time.Second).String(),
+	}
+	if b.total > 0 {
+		attrs = append(attrs, "percent", 100*current/b.total)
+	}
+	if !complete && rate > 0 && b.total > 0 {
+		eta := time.Duration(float64(b.total-current) / rate * float64(time.Second))
+		attrs = append(attrs, "eta", eta.Round(time.Second).String())
+	}
+
+	switch {
+	case !complete:
+		slog.Info("progress", attrs...)
+	case b.subtask:
+		slog.Debug("done", attrs...)
+	default:
+		slog.Info("done", attrs...)
+	}
+}
+
+// shouldLog is whether enough time has passed to log progress again. Bars
+// always log once on completion.
+func (b *Bar) shouldLog(complete bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.lastLog.IsZero() {
+		// Completion was already logged.
+		return false
+	}
+
+	now := time.Now()
+	switch {
+	case complete:
+		b.lastLog = time.Time{}
+		return true
+	case now.Sub(b.lastLog) >= b.interval:
+		b.lastLog = now
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,153 @@
+// Package rejects decides what happens to input files which can't be read,
+// such as ones holding malformed JSON: whether the command fails, or skips
+// them and lists them in a report.
+package rejects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Policy is what to do with a file which can't be read.
+type Policy string
+
+const (
+	// Fail stops the command at the first bad file.
+	Fail Policy = "fail"
+	// Skip leaves bad files out of the output and reports them.
+	Skip Policy = "skip"
+	// Quarantine skips bad files, and copies them to a directory for
+	// inspection.
+	Quarantine Policy = "quarantine"
+)
+
+var ErrRejects = errors.New("handling bad input file")
+
+// Reject is a line of the rejects report.
+type Reject struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+	// Quarantined is where the file was copied to, if it was.
+	Quarantined string `json:"quarantined,omitempty"`
+}
+
+// Handler applies a Policy to bad files. It is safe to use from multiple
+// goroutines.
+type Handler struct {
+	Policy Policy
+
+	// QuarantineDir is where bad files are copied to under the Quarantine
+	// policy, keeping their paths relative to Root.
+	QuarantineDir string
+	// Root is the input directory. If a file isn't under Root, it is
+	// quarantined by its name alone.
+	Root string
+
+	// Report, if set, is written one JSON Reject per line.
+	Report io.Writer
+
+	mu       sync.Mutex
+	rejected int
+}
+
+// Handle decides what happens to the file at path, which couldn't be read
+// because of err. It returns err if the command should fail, and otherwise
+// nil after reporting and possibly quarantining the file.
+func (h *Handler) Handle(path string, err error) error {
+	if h == nil || h.Policy == Fail || h.Policy == "" {
+		return err
+	}
+
+	reject := Reject{Path: path, Error: err.Error()}
+	if h.Policy == Quarantine {
+		quarantined, quarantineErr := h.quarantine(path)
+		if quarantineErr != nil {
+			return fmt.Errorf("%w: %w", ErrRejects, quarantineErr)
+		}
+		reject.Quarantined = quarantined
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.rejected++
+	slog.Warn("skipping bad file", "path", path, "err", err)
+
+	if h.Report == nil {
+		return nil
+	}
+
+	line, marshalErr := json.Marshal(reject)
+	if marshalErr != nil {
+		return fmt.Errorf("%w: %w", ErrRejects, marshalErr)
+	}
+
+	_, writeErr := fmt.Fprintf(h.Report, "%s\n", line)
+	if writeErr != nil {
+		return fmt.Errorf("%w: writing rejects report: %w", ErrRejects, writeErr)
+	}
+
+	return nil
+}
+
+// Rejected is the number of files skipped so far.
+func (h *Handler) Rejected() int {
+	if h == nil {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.rejected
+}
+
+func (h *Handler) quarantine(path string) (string, error) {
+	// Keep the path relative to Root, unless path is Root itself or outside it.
+	rel := filepath.Base(path)
+	if h.Root != "" {
+		r, err := filepath.Rel(h.Root, path)
+		if err == nil && r != "." && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			rel = r
+		}
+	}
+	outPath := filepath.Join(h.QuarantineDir, rel)
+
+	err := os.MkdirAll(filepath.Dir(outPath), os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("creating quarantine directory for %q: %w", path, err)
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening %q to quarantine: %w", path, err)
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return "", fmt.Errorf("creating %q: %w", outPath, err)
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		return "", fmt.Errorf("copying %q to %q: %w", path, outPath, err)
+	}
+
+	err = out.Close()
+	if err != nil {
+		return "", fmt.Errorf("closing %q: %w", outPath, err)
+	}
+
+	return outPath, nil
+}
@@ -0,0 +1,94 @@
+package tables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/parquet/file"
+	"github.com/apache/arrow/go/v18/parquet/pqarrow"
+	"io"
+	"iter"
+)
+
+var ErrRead = errors.New("reading table")
+
+// Records iterates over the records of the Parquet file at path. Each record is
+// only valid until the next iteration.
+func Records(ctx context.Context, path string) iter.Seq2[arrow.Record, error] {
+	return func(yield func(arrow.Record, error) bool) {
+		parquetReader, err := file.OpenParquetFile(path, true)
+		if err != nil {
+			yield(nil, fmt.Errorf("%w: opening %q: %w", ErrRead, path, err))
+			return
+		}
+		defer func() {
+			_ = parquetReader.Close()
+		}()
+
+		fileReader, err := pqarrow.NewFileReader(
+			parquetReader,
+			pqarrow.ArrowReadProperties{
+				Parallel:  true,
+				BatchSize: 1 << 20,
+			},
+			nil)
+		if err != nil {
+			yield(nil, fmt.Errorf("%w: %q: %w", ErrRead, path, err))
+			return
+		}
+
+		recordReader, err := fileReader.GetRecordReader(ctx, nil, nil)
+		if err != nil {
+			yield(nil, fmt.Errorf("%w: %q: %w", ErrRead, path, err))
+			return
+		}
+		defer recordReader.Release()
+
+		for {
+			record, err := recordReader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			} else if err != nil {
+				yield(nil, fmt.Errorf("%w: %q: %w", ErrRead, path, err))
+				return
+			}
+
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Column returns the column of record called name.
+func Column(record arrow.Record, name string) (arrow.Array, error) {
+	indices := record.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("%w: no column %q", ErrRead, name)
+	}
+
+	return record.Column(indices[0]), nil
+}
+
+// StringValue is the value of a string or dictionary-encoded string column at
+// row, or "" if it is null.
+func StringValue(column arrow.Array, row int) string {
+	if column.IsNull(row) {
+		return ""
+	}
+
+	switch column := column.(type) {
+	case *array.String:
+		return column.Value(row)
+	case *array.Dictionary:
+		dictionary, ok := column.Dictionary().(*array.String)
+		if !ok {
+			return column.Dictionary().ValueStr(column.GetValueIndex(row))
+		}
+		return dictionary.Value(column.GetValueIndex(row))
+	default:
+		return column.ValueStr(row)
+	}
+}
@@ -0,0 +1,94 @@
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/csv"
+	"github.com/apache/arrow/go/v18/parquet"
+	"github.com/apache/arrow/go/v18/parquet/compress"
+	"github.com/apache/arrow/go/v18/parquet/pqarrow"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+const CSVExt = ".csv"
+
+var ErrWrite = errors.New("writing table")
+
+// CheckFormat returns an error unless path ends in an extension Write knows,
+// so bad paths can be caught before doing the work of building a table.
+func CheckFormat(path string) error {
+	switch filepath.Ext(path) {
+	case CSVExt, ParquetExt:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q must end in %s or %s", ErrWrite, path, CSVExt, ParquetExt)
+	}
+}
+
+// Write writes record to path, as CSV with a header or as Parquet depending on
+// the extension of path.
+func Write(path string, record arrow.Record) error {
+	err := CheckFormat(path)
+	if err != nil {
+		return err
+	}
+
+	outFile, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWrite, err)
+	}
+
+	if filepath.Ext(path) == ParquetExt {
+		return writeParquet(outFile, record)
+	}
+
+	defer func() {
+		err := outFile.Close()
+		if err != nil {
+			slog.Error("closing output file", "err", err)
+		}
+	}()
+
+	writer := csv.NewWriter(outFile, record.Schema(), csv.WithHeader(true), csv.WithNullWriter(""))
+	err = writer.Write(record)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrWrite, path, err)
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrWrite, path, err)
+	}
+
+	return nil
+}
+
+func writeParquet(outFile *os.File, record arrow.Record) error {
+	// Don't close outFile; parquet handles closing it.
+	writer, err := pqarrow.NewFileWriter(
+		record.Schema(),
+		outFile,
+		parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Gzip)),
+		pqarrow.DefaultWriterProps(),
+	)
+	if err != nil {
+		_ = outFile.Close()
+		return fmt.Errorf("%w: %q: %w", ErrWrite, outFile.Name(), err)
+	}
+
+	err = writer.Write(record)
+	if err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("%w: %q: %w", ErrWrite, outFile.Name(), err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrWrite, outFile.Name(), err)
+	}
+
+	return nil
+}